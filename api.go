@@ -0,0 +1,430 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/internal/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	apiDefaultPostLimit = 20
+	apiMaxPostLimit     = 200
+)
+
+type apiUser struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	IsAdmin   bool      `json:"is_admin"`
+	Timezone  string    `json:"timezone"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type apiFeed struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	OwnerID       *uuid.UUID `json:"owner_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+type apiFollow struct {
+	ID        uuid.UUID `json:"id"`
+	FeedID    uuid.UUID `json:"feed_id"`
+	FeedName  string    `json:"feed_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type apiPost struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	Content     string     `json:"content,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	Categories  []string   `json:"categories"`
+	PublishedAt *time.Time `json:"published_at"`
+	FeedID      uuid.UUID  `json:"feed_id"`
+	FeedName    string     `json:"feed_name"`
+	Read        bool       `json:"read"`
+	Starred     bool       `json:"starred"`
+	Tags        []string   `json:"tags"`
+}
+
+func newAPIUser(user database.User) apiUser {
+	return apiUser{
+		ID:        user.ID,
+		Name:      user.Name,
+		IsAdmin:   user.IsAdmin,
+		Timezone:  user.Timezone,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func newAPIFeed(feed database.Feed) apiFeed {
+	f := apiFeed{
+		ID:        feed.ID,
+		Name:      feed.Name,
+		URL:       feed.Url,
+		CreatedAt: feed.CreatedAt,
+	}
+	if feed.UserID.Valid {
+		f.OwnerID = &feed.UserID.UUID
+	}
+	if feed.LastFetchedAt.Valid {
+		f.LastFetchedAt = &feed.LastFetchedAt.Time
+	}
+	return f
+}
+
+func newAPIPost(post database.GetPostsForUserRow) apiPost {
+	p := apiPost{
+		ID:          post.ID,
+		Title:       post.Title,
+		URL:         post.Url,
+		Description: post.Description.String,
+		Content:     post.Content.String,
+		Author:      post.Author.String,
+		Categories:  postCategories(post.Categories),
+		FeedID:      post.FeedID,
+		FeedName:    post.FeedName,
+		Read:        post.ReadAt.Valid,
+		Starred:     post.StarredAt.Valid,
+		Tags:        postTags(post),
+	}
+	if post.PublishedAt.Valid {
+		p.PublishedAt = &post.PublishedAt.Time
+	}
+	return p
+}
+
+func (srv *server) registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/me", srv.authenticated(srv.handleGetMe))
+	mux.HandleFunc("GET /api/users", srv.authenticated(srv.handleGetUsers))
+	mux.HandleFunc("GET /api/feeds", srv.authenticated(srv.handleGetFeeds))
+	mux.HandleFunc("POST /api/feeds", srv.authenticated(srv.handleCreateFeed))
+	mux.HandleFunc("GET /api/follows", srv.authenticated(srv.handleGetFollows))
+	mux.HandleFunc("POST /api/follows", srv.authenticated(srv.handleCreateFollow))
+	mux.HandleFunc("DELETE /api/follows", srv.authenticated(srv.handleDeleteFollow))
+	mux.HandleFunc("GET /api/posts", srv.authenticated(srv.handleGetPosts))
+	mux.HandleFunc("GET /api/feed", srv.feedAuthenticated(srv.handleGetOutputFeed))
+	mux.HandleFunc("PUT /api/posts/{id}/read", srv.authenticated(srv.handleSetPostState(setPostRead, true)))
+	mux.HandleFunc("DELETE /api/posts/{id}/read", srv.authenticated(srv.handleSetPostState(setPostRead, false)))
+	mux.HandleFunc("PUT /api/posts/{id}/star", srv.authenticated(srv.handleSetPostState(setPostStarred, true)))
+	mux.HandleFunc("DELETE /api/posts/{id}/star", srv.authenticated(srv.handleSetPostState(setPostStarred, false)))
+}
+
+func (srv *server) handleGetMe(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondWithJSON(w, http.StatusOK, newAPIUser(user))
+}
+
+func (srv *server) handleGetUsers(w http.ResponseWriter, r *http.Request, user database.User) {
+	users, err := srv.state.db.GetUsers(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to get users")
+		return
+	}
+
+	response := make([]apiUser, 0, len(users))
+	for _, u := range users {
+		response = append(response, newAPIUser(u))
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (srv *server) handleGetFeeds(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := srv.state.db.GetFeeds(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to get feeds")
+		return
+	}
+
+	response := make([]apiFeed, 0, len(feeds))
+	for _, feed := range feeds {
+		response = append(response, newAPIFeed(feed))
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// isWebURL reports whether raw is an absolute http or https URL, the only
+// kind of feed gator can fetch.
+func isWebURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (srv *server) handleCreateFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	var params parameters
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil || params.Name == "" || params.URL == "" {
+		respondWithError(w, http.StatusBadRequest, "name and url are required")
+		return
+	}
+
+	if !isWebURL(params.URL) {
+		respondWithError(w, http.StatusBadRequest, "url must be an absolute http or https URL")
+		return
+	}
+
+	result, err := addFeed(r.Context(), srv.state, user, params.Name, params.URL)
+	if storage.IsUniqueViolation(err) {
+		// Someone else added the same feed at the same time.
+		respondWithError(w, http.StatusConflict, "feed already exists, try again to follow it")
+		return
+	}
+	if err != nil {
+		log.Printf("failed to add feed %s: %v", params.URL, err)
+		respondWithError(w, http.StatusInternalServerError, "failed to add feed")
+		return
+	}
+
+	code := http.StatusOK
+	if result.Created {
+		code = http.StatusCreated
+	}
+	respondWithJSON(w, code, newAPIFeed(result.Feed))
+}
+
+func (srv *server) handleGetFollows(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := srv.state.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to get follows")
+		return
+	}
+
+	response := make([]apiFollow, 0, len(follows))
+	for _, follow := range follows {
+		response = append(response, apiFollow{
+			ID:        follow.ID,
+			FeedID:    follow.FeedID,
+			FeedName:  follow.FeedName,
+			CreatedAt: follow.CreatedAt,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (srv *server) handleCreateFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		FeedURL string `json:"feed_url"`
+	}
+	var params parameters
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil || params.FeedURL == "" {
+		respondWithError(w, http.StatusBadRequest, "feed_url is required")
+		return
+	}
+
+	follow, err := followFeed(r.Context(), srv.state, user, params.FeedURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "feed not found")
+		return
+	}
+	if storage.IsUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "already following this feed")
+		return
+	}
+	if err != nil {
+		log.Printf("failed to follow feed %s: %v", params.FeedURL, err)
+		respondWithError(w, http.StatusInternalServerError, "failed to follow feed")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, apiFollow{
+		ID:        follow.ID,
+		FeedID:    follow.FeedID,
+		FeedName:  follow.FeedName,
+		CreatedAt: follow.CreatedAt,
+	})
+}
+
+func (srv *server) handleDeleteFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedURL := r.URL.Query().Get("feed_url")
+	if feedURL == "" {
+		respondWithError(w, http.StatusBadRequest, "feed_url is required")
+		return
+	}
+
+	unfollowArgs := database.DeleteFeedFollowByUserAndFeedURLParams{
+		UserID: user.ID,
+		Url:    feedURL,
+	}
+	err := srv.state.db.DeleteFeedFollowByUserAndFeedURL(r.Context(), unfollowArgs)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to unfollow feed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// postFilterFromQuery reads the browse filters from limit, offset, unread,
+// starred, muted, feed_url, folder, tag and q query parameters.
+func postFilterFromQuery(r *http.Request) (postFilter, error) {
+	query := r.URL.Query()
+	filter := postFilter{
+		Limit:   apiDefaultPostLimit,
+		FeedURL: query.Get("feed_url"),
+		Folder:  query.Get("folder"),
+		Tag:     query.Get("tag"),
+		Query:   query.Get("q"),
+	}
+
+	var err error
+	if value := query.Get("limit"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil || filter.Limit < 1 {
+			return postFilter{}, invalidQueryError("limit")
+		}
+		filter.Limit = min(filter.Limit, apiMaxPostLimit)
+	}
+	if value := query.Get("offset"); value != "" {
+		filter.Offset, err = strconv.Atoi(value)
+		if err != nil || filter.Offset < 0 {
+			return postFilter{}, invalidQueryError("offset")
+		}
+	}
+	if value := query.Get("unread"); value != "" {
+		filter.UnreadOnly, err = strconv.ParseBool(value)
+		if err != nil {
+			return postFilter{}, invalidQueryError("unread")
+		}
+	}
+	if value := query.Get("starred"); value != "" {
+		filter.StarredOnly, err = strconv.ParseBool(value)
+		if err != nil {
+			return postFilter{}, invalidQueryError("starred")
+		}
+	}
+	if value := query.Get("muted"); value != "" {
+		filter.MutedOnly, err = strconv.ParseBool(value)
+		if err != nil {
+			return postFilter{}, invalidQueryError("muted")
+		}
+	}
+	return filter, nil
+}
+
+type invalidQueryError string
+
+func (e invalidQueryError) Error() string {
+	return "invalid value for " + string(e)
+}
+
+func (srv *server) handleGetPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	filter, err := postFilterFromQuery(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	posts, err := srv.state.db.GetPostsForUser(r.Context(), filter.params(user.ID))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to get posts")
+		return
+	}
+
+	response := make([]apiPost, 0, len(posts))
+	for _, post := range posts {
+		response = append(response, newAPIPost(post))
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+type postStateSetter func(ctx context.Context, db database.Querier, userID, postID uuid.UUID, value bool) error
+
+func (srv *server) handleSetPostState(set postStateSetter, value bool) func(http.ResponseWriter, *http.Request, database.User) {
+	return func(w http.ResponseWriter, r *http.Request, user database.User) {
+		postID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid post id")
+			return
+		}
+
+		_, err = getFollowedPost(r.Context(), srv.state.db, user.ID, postID)
+		if err == sql.ErrNoRows {
+			respondWithError(w, http.StatusNotFound, "post not found")
+			return
+		}
+		if err != nil {
+			log.Printf("failed to get post: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "failed to get post")
+			return
+		}
+
+		err = set(r.Context(), srv.state.db, user.ID, postID, value)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleGetOutputFeed renders the posts matched by the browse filters as an
+// RSS feed, or as Atom with format=atom. The limit defaults to that of
+// feedout.
+func (srv *server) handleGetOutputFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	filter, err := postFilterFromQuery(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if r.URL.Query().Get("limit") == "" {
+		filter.Limit = outputDefaultLimit
+	}
+
+	atom := false
+	switch r.URL.Query().Get("format") {
+	case "", "rss":
+	case "atom":
+		atom = true
+	default:
+		respondWithError(w, http.StatusBadRequest, invalidQueryError("format").Error())
+		return
+	}
+
+	posts, err := srv.state.db.GetPostsForUser(r.Context(), filter.params(user.ID))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to get posts")
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	// The self link ends up in the feed, which may be shared or cached, so
+	// it must not carry the API token.
+	self := *r.URL
+	query := self.Query()
+	query.Del("token")
+	self.RawQuery = query.Encode()
+	selfURL := scheme + "://" + r.Host + self.RequestURI()
+
+	data, err := renderOutputFeed(buildOutputFeed(user, filter, posts, outputDefaultLink, selfURL), atom)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to render feed")
+		return
+	}
+
+	contentType := "application/rss+xml"
+	if atom {
+		contentType = "application/atom+xml"
+	}
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+)
+
+func TestHandleCreateFollow(t *testing.T) {
+	s := newTestState(t)
+	srv := &server{state: s}
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	createTestFeed(t, s, alice, "https://example.com/followed")
+	createTestFeed(t, s, bob, "https://example.com/other")
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"missing url", `{}`, http.StatusBadRequest},
+		{"invalid json", `{`, http.StatusBadRequest},
+		{"unknown feed", `{"feed_url": "https://example.com/unknown"}`, http.StatusNotFound},
+		{"already following", `{"feed_url": "https://example.com/followed"}`, http.StatusConflict},
+		{"new follow", `{"feed_url": "https://example.com/other"}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/follows", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			srv.handleCreateFollow(w, r, alice)
+			if w.Code != tt.want {
+				t.Errorf("status = %d (%s), want %d", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleSetPostStateRequiresFollow(t *testing.T) {
+	s := newTestState(t)
+	srv := &server{state: s}
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	feed := createTestFeed(t, s, alice, "https://example.com/feed")
+	post := createTestPost(t, s, feed, "https://example.com/a", time.Now().UTC())
+
+	tests := []struct {
+		name string
+		user database.User
+		want int
+	}{
+		{"follower", alice, http.StatusNoContent},
+		{"not following", bob, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/api/posts/"+post.ID.String()+"/read", nil)
+			r.SetPathValue("id", post.ID.String())
+			w := httptest.NewRecorder()
+
+			srv.handleSetPostState(setPostRead, true)(w, r, tt.user)
+			if w.Code != tt.want {
+				t.Errorf("status = %d (%s), want %d", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleGetPostsByFolder(t *testing.T) {
+	s := newTestState(t)
+	srv := &server{state: s}
+	alice := createTestUser(t, s, "alice")
+	news := createTestFeed(t, s, alice, "https://example.com/news")
+	blog := createTestFeed(t, s, alice, "https://example.com/blog")
+	createTestPost(t, s, news, "https://example.com/news/1", time.Now().UTC())
+	createTestPost(t, s, blog, "https://example.com/blog/1", time.Now().UTC())
+	if _, err := setFolder(context.Background(), s.db, alice, news.Url, "News"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"https://example.com/blog/1", "https://example.com/news/1"}},
+		{"?folder=News", []string{"https://example.com/news/1"}},
+		{"?folder=Empty", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/posts"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			srv.handleGetPosts(w, r, alice)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d (%s)", w.Code, w.Body)
+			}
+			var posts []apiPost
+			if err := json.Unmarshal(w.Body.Bytes(), &posts); err != nil {
+				t.Fatal(err)
+			}
+			var urls []string
+			for _, post := range posts {
+				urls = append(urls, post.URL)
+			}
+			sort.Strings(urls)
+			if strings.Join(urls, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("posts = %v, want %v", urls, tt.want)
+			}
+		})
+	}
+}
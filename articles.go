@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/internal/htmltext"
+	"github.com/Romasav/gator/internal/readability"
+)
+
+// fetchArticles downloads the page behind every post and stores the
+// extracted article next to it. Pages that can not be fetched or hold no
+// article are reported through warn; the post keeps just its description.
+func fetchArticles(s *state, posts []database.Post, warn func(format string, args ...interface{})) {
+	for i, post := range posts {
+		article, err := readability.Fetch(context.Background(), post.Url)
+		if err != nil {
+			warn("Error fetching article %s: %v", post.Url, err)
+			continue
+		}
+
+		setPostArticleParams := database.SetPostArticleParams{
+			ID:               post.ID,
+			ArticleHtml:      sql.NullString{String: article.HTML, Valid: true},
+			ArticleText:      sql.NullString{String: article.Text, Valid: true},
+			ArticleFetchedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		}
+		err = s.db.SetPostArticle(context.Background(), setPostArticleParams)
+		if err != nil {
+			warn("Error saving article %s: %v", post.Url, err)
+			continue
+		}
+		posts[i].ArticleHtml = setPostArticleParams.ArticleHtml
+		posts[i].ArticleText = setPostArticleParams.ArticleText
+		posts[i].ArticleFetchedAt = setPostArticleParams.ArticleFetchedAt
+	}
+}
+
+// postBody renders the readable text of a post, wrapped at width columns
+// or not at all if width is 0: the extracted article if there is one,
+// otherwise the feed's full content or its description.
+func postBody(description, content, articleHTML sql.NullString, width int) string {
+	if articleHTML.Valid && articleHTML.String != "" {
+		return htmltext.Render(articleHTML.String, width)
+	}
+	if content.Valid && content.String != "" {
+		return htmltext.Render(content.String, width)
+	}
+	return htmltext.Render(description.String, width)
+}
+
+func feedFullContent(s *state, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("feed fullcontent requires exactly 2 arguments (feed URL, on or off), found %v arguments", len(args))
+	}
+	var enabled bool
+	switch args[1] {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		return fmt.Errorf("expected on or off, found %s", args[1])
+	}
+
+	feed, err := s.db.GetFeedByURL(context.Background(), args[0])
+	if err == sql.ErrNoRows {
+		return errors.New("the feed dose not exists")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find feed by url: %w", err)
+	}
+
+	setFeedFetchFullContentParams := database.SetFeedFetchFullContentParams{
+		ID:               feed.ID,
+		FetchFullContent: enabled,
+		UpdatedAt:        time.Now().UTC(),
+	}
+	_, err = s.db.SetFeedFetchFullContent(context.Background(), setFeedFetchFullContentParams)
+	if err != nil {
+		return fmt.Errorf("failed to update feed: %w", err)
+	}
+
+	if enabled {
+		fmt.Printf("Full articles will be fetched for new posts of '%s'\n", feed.Url)
+		return nil
+	}
+	fmt.Printf("Only the feed content will be stored for new posts of '%s'\n", feed.Url)
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const sessionDuration = 30 * 24 * time.Hour
+
+var errNotLoggedIn = errors.New("you are not logged in, run \"gator login <username>\" first")
+
+// promptNewPassword asks for an optional password twice and returns its
+// bcrypt hash, or an invalid NullString when the user leaves it empty.
+func promptNewPassword() (sql.NullString, error) {
+	password, err := readPassword("Password (leave empty for none): ")
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	if password == "" {
+		return sql.NullString{}, nil
+	}
+
+	repeated, err := readPassword("Repeat password: ")
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	if password != repeated {
+		return sql.NullString{}, errors.New("the passwords do not match")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+	return sql.NullString{String: string(hash), Valid: true}, nil
+}
+
+func checkPassword(user database.User, password string) error {
+	if !user.HashedPassword.Valid {
+		return nil
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(user.HashedPassword.String), []byte(password))
+	if err != nil {
+		return errors.New("wrong password")
+	}
+	return nil
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashToken is what gets stored in the database, so a leaked database does
+// not leak usable tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// createSession stores a new session for user and returns its token.
+func createSession(ctx context.Context, db database.Querier, user database.User) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	createSessionParams := database.CreateSessionParams{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: time.Now().UTC().Add(sessionDuration),
+	}
+	_, err = db.CreateSession(ctx, createSessionParams)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+	return token, nil
+}
+
+func startSession(s *state, user database.User) error {
+	token, err := createSession(context.Background(), s.db, user)
+	if err != nil {
+		return err
+	}
+
+	err = s.config.SetSession(token)
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+func currentUser(s *state) (database.User, error) {
+	if s.config.SessionToken == "" {
+		return database.User{}, errNotLoggedIn
+	}
+
+	getUserBySessionParams := database.GetUserBySessionParams{
+		TokenHash: hashToken(s.config.SessionToken),
+		Now:       time.Now().UTC(),
+	}
+	user, err := s.db.GetUserBySession(context.Background(), getUserBySessionParams)
+	if err == sql.ErrNoRows {
+		return database.User{}, errors.New("your session is invalid or has expired, run \"gator login <username>\" again")
+	}
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to look up session: %w", err)
+	}
+	return user, nil
+}
@@ -41,3 +41,37 @@ func newCommand(name string, args []string) *command {
 		Arguments: args,
 	}
 }
+
+// popFlag removes every occurrence of flag from args and reports whether it
+// was present, so flags can appear anywhere among the positional arguments.
+func popFlag(args []string, flag string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	found := false
+	for _, arg := range args {
+		if arg == flag {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
+}
+
+// popFlagValue removes flag and the value following it from args and
+// returns that value, or an empty string if the flag is absent.
+func popFlagValue(args []string, flag string) ([]string, string, error) {
+	rest := make([]string, 0, len(args))
+	value := ""
+	for i := 0; i < len(args); i++ {
+		if args[i] != flag {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, "", fmt.Errorf("%v requires a value", flag)
+		}
+		value = args[i+1]
+		i++
+	}
+	return rest, value, nil
+}
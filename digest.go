@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/internal/htmltext"
+	"github.com/Romasav/gator/internal/mailer"
+	"github.com/google/uuid"
+)
+
+const (
+	// digestFirstWindow is how far back the first digest of a user looks.
+	digestFirstWindow = 24 * time.Hour
+	digestMaxPosts    = 200
+	digestSummaryLen  = 300
+)
+
+//go:embed templates/digest.txt
+var digestTextSource string
+
+var digestTextTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"formatTime": formatTime,
+}).Parse(digestTextSource))
+
+type digestData struct {
+	Subject  string
+	User     database.User
+	Location *time.Location
+	Since    time.Time
+	// Until is when the newest post in the digest arrived, or when it was
+	// built if there are none. The next digest starts from there, after
+	// UntilID if the digest was cut short.
+	Until   time.Time
+	UntilID uuid.NullUUID
+	Count   int
+	// Truncated is set when more posts arrived than fit in one digest.
+	Truncated bool
+	Feeds     []digestFeed
+}
+
+type digestFeed struct {
+	Name  string
+	URL   string
+	Posts []digestPost
+}
+
+type digestPost struct {
+	Title     string
+	URL       string
+	Summary   string
+	Published time.Time
+}
+
+func handlerEmail(s *state, cmd command, user database.User) error {
+	args, clear := popFlag(cmd.Arguments, "--clear")
+	if len(args) > 1 || (clear && len(args) > 0) {
+		return fmt.Errorf("email takes at most 1 argument (address), found %v arguments", len(args))
+	}
+	if len(args) == 0 && !clear {
+		if !user.Email.Valid {
+			fmt.Println("You have no email address set, so you do not get digests")
+			return nil
+		}
+		fmt.Printf("Digests are sent to %s\n", user.Email.String)
+		return nil
+	}
+
+	updateUserEmailParams := database.UpdateUserEmailParams{
+		ID:        user.ID,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if !clear {
+		address, err := mail.ParseAddress(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid email address %s: %w", args[0], err)
+		}
+		updateUserEmailParams.Email = sql.NullString{String: address.Address, Valid: true}
+	}
+	_, err := s.db.UpdateUserEmail(context.Background(), updateUserEmailParams)
+	if err != nil {
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	if clear {
+		fmt.Println("Your email address has been removed, no more digests will be sent")
+		return nil
+	}
+	fmt.Printf("Digests will be sent to %s\n", updateUserEmailParams.Email.String)
+	return nil
+}
+
+// handlerDigest mails every user with an email address the unread posts
+// that arrived since their previous digest. It is meant to be run from cron
+// or a systemd timer.
+func handlerDigest(s *state, cmd command) error {
+	args, dryRun := popFlag(cmd.Arguments, "--dry-run")
+	if len(args) != 0 {
+		return fmt.Errorf("digest dosent require any arguments, found %v arguments", args)
+	}
+	smtpConfig := s.config.SMTP
+	if smtpConfig == nil && !dryRun {
+		return errors.New("no smtp server configured, add an \"smtp\" section to the config file")
+	}
+
+	users, err := s.db.GetUsersWithEmail(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	failed := 0
+	for _, user := range users {
+		until := time.Now().UTC()
+		since := until.Add(-digestFirstWindow)
+		if user.LastDigestAt.Valid {
+			since = user.LastDigestAt.Time
+		}
+
+		data, err := buildDigest(s, user, since, user.LastDigestPostID, until)
+		if err != nil {
+			fmt.Printf("Error building digest for %s: %v\n", user.Name, err)
+			failed++
+			continue
+		}
+
+		if data.Count == 0 {
+			fmt.Printf("No new posts for %s\n", user.Name)
+		} else {
+			text, html, err := renderDigest(data)
+			if err != nil {
+				fmt.Printf("Error rendering digest for %s: %v\n", user.Name, err)
+				failed++
+				continue
+			}
+			if dryRun {
+				fmt.Printf("To: %s\nSubject: %s\n\n%s\n", user.Email.String, data.Subject, text)
+				continue
+			}
+
+			msg := mailer.Message{
+				From:    smtpConfig.From,
+				To:      user.Email.String,
+				Subject: data.Subject,
+				Text:    text,
+				HTML:    html,
+			}
+			mailerConfig := mailer.Config{
+				Host:     smtpConfig.Host,
+				Port:     smtpConfig.Port,
+				Username: smtpConfig.Username,
+				Password: smtpConfig.Password,
+			}
+			err = mailer.Send(mailerConfig, msg)
+			if err != nil {
+				fmt.Printf("Error sending digest to %s: %v\n", user.Name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Sent %v posts to %s\n", data.Count, user.Email.String)
+			if data.Truncated {
+				fmt.Printf("More posts are waiting for %s, they go out with the next digest\n", user.Name)
+			}
+		}
+		if dryRun {
+			continue
+		}
+
+		setUserLastDigestParams := database.SetUserLastDigestParams{
+			ID:               user.ID,
+			LastDigestAt:     sql.NullTime{Time: data.Until, Valid: true},
+			LastDigestPostID: data.UntilID,
+		}
+		err = s.db.SetUserLastDigest(context.Background(), setUserLastDigestParams)
+		if err != nil {
+			return fmt.Errorf("failed to update last digest time: %w", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to send %v of %v digests", failed, len(users))
+	}
+	return nil
+}
+
+// buildDigest collects the posts that arrived after since, or at since
+// after the post sinceID, grouped by feed name with the newest first. At
+// most digestMaxPosts are included, the oldest ones, and Until and UntilID
+// are moved back to the last of them so the rest are sent next time.
+func buildDigest(s *state, user database.User, since time.Time, sinceID uuid.NullUUID, until time.Time) (digestData, error) {
+	getPostsForDigestParams := database.GetPostsForDigestParams{
+		UserID:  user.ID,
+		Since:   since,
+		SinceID: sinceID,
+		Until:   until,
+		Limit:   digestMaxPosts + 1,
+	}
+	posts, err := s.db.GetPostsForDigest(context.Background(), getPostsForDigestParams)
+	if err != nil {
+		return digestData{}, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	data := digestData{
+		User:     user,
+		Location: userLocation(user),
+		Since:    since,
+		Until:    until,
+	}
+	if len(posts) > digestMaxPosts {
+		data.Truncated = true
+		posts = posts[:digestMaxPosts]
+		// Posts that arrived at the same moment as the last one are told
+		// apart by their id, so none of them are skipped next time.
+		data.Until = posts[len(posts)-1].CreatedAt
+		data.UntilID = uuid.NullUUID{UUID: posts[len(posts)-1].ID, Valid: true}
+	}
+	data.Count = len(posts)
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		if posts[i].FeedName != posts[j].FeedName {
+			return posts[i].FeedName < posts[j].FeedName
+		}
+		return digestPublished(posts[i]).After(digestPublished(posts[j]))
+	})
+	for _, post := range posts {
+		if len(data.Feeds) == 0 || data.Feeds[len(data.Feeds)-1].URL != post.FeedUrl {
+			data.Feeds = append(data.Feeds, digestFeed{Name: post.FeedName, URL: post.FeedUrl})
+		}
+		feed := &data.Feeds[len(data.Feeds)-1]
+		feed.Posts = append(feed.Posts, digestPost{
+			Title:     post.Title,
+			URL:       post.Url,
+			Summary:   truncateText(htmltext.Summary(post.Description.String), digestSummaryLen),
+			Published: digestPublished(post),
+		})
+	}
+
+	data.Subject = fmt.Sprintf("gator digest: %v new posts", data.Count)
+	if data.Count == 1 {
+		data.Subject = "gator digest: 1 new post"
+	}
+	return data, nil
+}
+
+func digestPublished(post database.GetPostsForDigestRow) time.Time {
+	if post.PublishedAt.Valid {
+		return post.PublishedAt.Time
+	}
+	return post.CreatedAt
+}
+
+func renderDigest(data digestData) (string, string, error) {
+	var text, html bytes.Buffer
+	err := digestTextTemplate.Execute(&text, data)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to render digest: %w", err)
+	}
+	err = uiTemplates.ExecuteTemplate(&html, "digest.html", data)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to render digest: %w", err)
+	}
+	return text.String(), html.String(), nil
+}
+
+// truncateText cuts s to at most n runes, ending it with an ellipsis if
+// anything was removed.
+func truncateText(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+)
+
+// sendTestDigests builds digests for user the way handlerDigest does,
+// moving the user's cursor after each, until one comes back empty. It
+// returns the URLs of the posts of every digest.
+func sendTestDigests(t *testing.T, s *state, user database.User, since, until time.Time) [][]string {
+	t.Helper()
+	var digests [][]string
+	for range 10 {
+		user, err := s.db.GetUserById(context.Background(), user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.LastDigestAt.Valid {
+			since = user.LastDigestAt.Time
+		}
+
+		data, err := buildDigest(s, user, since, user.LastDigestPostID, until)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data.Count == 0 {
+			return digests
+		}
+		var urls []string
+		for _, feed := range data.Feeds {
+			for _, post := range feed.Posts {
+				urls = append(urls, post.URL)
+			}
+		}
+		if len(urls) != data.Count {
+			t.Errorf("digest lists %d posts but counts %d", len(urls), data.Count)
+		}
+		if data.Truncated != (data.Count == digestMaxPosts) {
+			t.Errorf("digest of %d posts has Truncated %v", data.Count, data.Truncated)
+		}
+		digests = append(digests, urls)
+
+		setUserLastDigestParams := database.SetUserLastDigestParams{
+			ID:               user.ID,
+			LastDigestAt:     sql.NullTime{Time: data.Until, Valid: true},
+			LastDigestPostID: data.UntilID,
+		}
+		err = s.db.SetUserLastDigest(context.Background(), setUserLastDigestParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Fatal("digests never ran out of posts")
+	return nil
+}
+
+func TestBuildDigestCarriesOverPosts(t *testing.T) {
+	arrived := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		// createdAt returns when the i-th post arrived.
+		createdAt func(i int) time.Time
+		want      []int
+	}{
+		{"all at once", func(i int) time.Time { return arrived }, []int{200, 200, 50}},
+		{"one per second", func(i int) time.Time { return arrived.Add(time.Duration(i) * time.Second) }, []int{200, 200, 50}},
+		{"ties across the limit", func(i int) time.Time { return arrived.Add(time.Duration(i/150) * time.Second) }, []int{200, 200, 50}},
+		{"fits in one", func(i int) time.Time { return arrived }, []int{120}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestState(t)
+			user := createTestUser(t, s, "alice")
+			feed := createTestFeed(t, s, user, "https://example.com/feed")
+
+			total := 0
+			for _, n := range tt.want {
+				total += n
+			}
+			for i := range total {
+				createTestPost(t, s, feed, fmt.Sprintf("https://example.com/%d", i), tt.createdAt(i))
+			}
+
+			digests := sendTestDigests(t, s, user, arrived.Add(-time.Hour), arrived.Add(time.Hour))
+			seen := map[string]bool{}
+			var sizes []int
+			for _, urls := range digests {
+				sizes = append(sizes, len(urls))
+				for _, url := range urls {
+					if seen[url] {
+						t.Errorf("%s was sent twice", url)
+					}
+					seen[url] = true
+				}
+			}
+			if fmt.Sprint(sizes) != fmt.Sprint(tt.want) {
+				t.Errorf("digest sizes = %v, want %v", sizes, tt.want)
+			}
+			if len(seen) != total {
+				t.Errorf("%d of %d posts were sent", len(seen), total)
+			}
+		})
+	}
+}
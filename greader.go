@@ -0,0 +1,804 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/internal/storage"
+	"github.com/Romasav/gator/rssFeed"
+	"github.com/google/uuid"
+)
+
+// The Google Reader API is what most mobile feed readers speak. Only the
+// subset needed to sync subscriptions and read/starred state is supported.
+const (
+	greaderTokenName  = "greader"
+	greaderMaxItems   = 1000
+	greaderFeedPrefix = "feed/"
+	greaderItemPrefix = "tag:google.com,2005:reader/item/"
+	// Folders are exposed as labels.
+	greaderLabelPrefix = "user/-/label/"
+
+	greaderStreamReadingList = "user/-/state/com.google/reading-list"
+	greaderStreamRead        = "user/-/state/com.google/read"
+	greaderStreamKeptUnread  = "user/-/state/com.google/kept-unread"
+	greaderStreamStarred     = "user/-/state/com.google/starred"
+)
+
+type greaderSubscription struct {
+	ID         string            `json:"id"`
+	Title      string            `json:"title"`
+	Categories []greaderCategory `json:"categories"`
+	URL        string            `json:"url"`
+	HTMLURL    string            `json:"htmlUrl"`
+	IconURL    string            `json:"iconUrl"`
+}
+
+type greaderCategory struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type greaderItemRef struct {
+	ID              string   `json:"id"`
+	DirectStreamIDs []string `json:"directStreamIds"`
+	TimestampUsec   string   `json:"timestampUsec"`
+}
+
+type greaderLink struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type greaderContent struct {
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+type greaderOrigin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type greaderItem struct {
+	ID            string         `json:"id"`
+	CrawlTimeMsec string         `json:"crawlTimeMsec"`
+	TimestampUsec string         `json:"timestampUsec"`
+	Published     int64          `json:"published"`
+	Updated       int64          `json:"updated"`
+	Title         string         `json:"title"`
+	Canonical     []greaderLink  `json:"canonical"`
+	Alternate     []greaderLink  `json:"alternate"`
+	Summary       greaderContent `json:"summary"`
+	Categories    []string       `json:"categories"`
+	Origin        greaderOrigin  `json:"origin"`
+	Author        string         `json:"author"`
+}
+
+// greaderItemID is the short form of an item id: the first 8 bytes of the
+// post id as a signed decimal.
+func greaderItemID(id uuid.UUID) string {
+	return strconv.FormatInt(int64(binary.BigEndian.Uint64(id[:8])), 10)
+}
+
+func greaderLongItemID(id uuid.UUID) string {
+	return greaderItemPrefix + hex.EncodeToString(id[:8])
+}
+
+// parseGReaderItemID accepts both the short and the long form of an item id
+// and returns the range of post ids it refers to: those starting with the
+// 8 bytes the item id carries.
+func parseGReaderItemID(value string) (uuid.UUID, uuid.UUID, error) {
+	var n uint64
+	if hexID, found := strings.CutPrefix(value, greaderItemPrefix); found {
+		var err error
+		n, err = strconv.ParseUint(hexID, 16, 64)
+		if err != nil {
+			return uuid.UUID{}, uuid.UUID{}, fmt.Errorf("invalid item id %q", value)
+		}
+	} else {
+		signed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			u, uerr := strconv.ParseUint(value, 10, 64)
+			if uerr != nil {
+				return uuid.UUID{}, uuid.UUID{}, fmt.Errorf("invalid item id %q", value)
+			}
+			signed = int64(u)
+		}
+		n = uint64(signed)
+	}
+
+	var minID, maxID uuid.UUID
+	binary.BigEndian.PutUint64(minID[:8], n)
+	binary.BigEndian.PutUint64(maxID[:8], n)
+	binary.BigEndian.PutUint64(maxID[8:], math.MaxUint64)
+	return minID, maxID, nil
+}
+
+// normalizeGReaderStream rewrites user/<id>/... stream ids to the
+// user/-/... form, which is what clients send most of the time anyway.
+func normalizeGReaderStream(streamID string) string {
+	rest, found := strings.CutPrefix(streamID, "user/")
+	if !found {
+		return streamID
+	}
+	_, path, found := strings.Cut(rest, "/")
+	if !found {
+		return streamID
+	}
+	return "user/-/" + path
+}
+
+func newGReaderItem(post database.GetPostsForUserRow) greaderItem {
+	published := post.CreatedAt
+	if post.PublishedAt.Valid {
+		published = post.PublishedAt.Time
+	}
+
+	categories := []string{greaderStreamReadingList}
+	if post.ReadAt.Valid {
+		categories = append(categories, greaderStreamRead)
+	}
+	if post.StarredAt.Valid {
+		categories = append(categories, greaderStreamStarred)
+	}
+
+	summary := post.Description.String
+	if post.Content.Valid && post.Content.String != "" {
+		summary = post.Content.String
+	}
+
+	return greaderItem{
+		ID:            greaderLongItemID(post.ID),
+		CrawlTimeMsec: strconv.FormatInt(post.CreatedAt.UnixMilli(), 10),
+		TimestampUsec: strconv.FormatInt(post.CreatedAt.UnixMicro(), 10),
+		Published:     published.Unix(),
+		Updated:       post.UpdatedAt.Unix(),
+		Title:         post.Title,
+		Canonical:     []greaderLink{{Href: post.Url}},
+		Alternate:     []greaderLink{{Href: post.Url, Type: "text/html"}},
+		Summary:       greaderContent{Direction: "ltr", Content: summary},
+		Categories:    categories,
+		Origin: greaderOrigin{
+			StreamID: greaderFeedPrefix + post.FeedUrl,
+			Title:    post.FeedName,
+			HTMLURL:  post.FeedUrl,
+		},
+		Author: post.Author.String,
+	}
+}
+
+func (srv *server) registerGReader(mux *http.ServeMux) {
+	mux.HandleFunc("/accounts/ClientLogin", srv.handleGReaderLogin)
+
+	const prefix = "/reader/api/0/"
+	mux.HandleFunc("GET "+prefix+"token", srv.greaderAuthenticated(srv.handleGReaderToken))
+	mux.HandleFunc("GET "+prefix+"user-info", srv.greaderAuthenticated(srv.handleGReaderUserInfo))
+	mux.HandleFunc("GET "+prefix+"subscription/list", srv.greaderAuthenticated(srv.handleGReaderSubscriptions))
+	mux.HandleFunc("POST "+prefix+"subscription/edit", srv.greaderAuthenticated(srv.handleGReaderEditSubscription))
+	mux.HandleFunc("POST "+prefix+"subscription/quickadd", srv.greaderAuthenticated(srv.handleGReaderQuickAdd))
+	mux.HandleFunc("GET "+prefix+"tag/list", srv.greaderAuthenticated(srv.handleGReaderTags))
+	mux.HandleFunc("GET "+prefix+"unread-count", srv.greaderAuthenticated(srv.handleGReaderUnreadCount))
+	mux.HandleFunc("GET "+prefix+"stream/contents", srv.greaderAuthenticated(srv.handleGReaderStreamContents))
+	mux.HandleFunc("GET "+prefix+"stream/contents/{stream...}", srv.greaderAuthenticated(srv.handleGReaderStreamContents))
+	mux.HandleFunc("GET "+prefix+"stream/items/ids", srv.greaderAuthenticated(srv.handleGReaderItemIDs))
+	mux.HandleFunc(prefix+"stream/items/contents", srv.greaderAuthenticated(srv.handleGReaderItemContents))
+	mux.HandleFunc("POST "+prefix+"edit-tag", srv.greaderAuthenticated(srv.handleGReaderEditTag))
+	mux.HandleFunc("POST "+prefix+"mark-all-as-read", srv.greaderAuthenticated(srv.handleGReaderMarkAllAsRead))
+}
+
+// greaderAuthenticated resolves the "GoogleLogin auth=<token>" header that
+// ClientLogin hands out. The token is an ordinary API token.
+func (srv *server) greaderAuthenticated(handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "GoogleLogin auth=")
+		if !found || token == "" {
+			respondWithText(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		user, err := srv.userForAPIToken(r.Context(), token)
+		if err != nil {
+			respondWithText(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		handler(w, r, user)
+	}
+}
+
+func respondWithText(w http.ResponseWriter, code int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	fmt.Fprint(w, text)
+}
+
+// handleGReaderLogin checks the username and password and issues a new API
+// token, which replaces the one of the previous login. Users without a
+// password cannot log in this way.
+func (srv *server) handleGReaderLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		// Credentials in the query string end up in logs.
+		w.Header().Set("Allow", http.MethodPost)
+		respondWithText(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	user, err := srv.state.db.GetUser(r.Context(), r.PostFormValue("Email"))
+	if err != nil || !user.HashedPassword.Valid || checkPassword(user, r.PostFormValue("Passwd")) != nil {
+		respondWithText(w, http.StatusForbidden, "Error=BadAuthentication\n")
+		return
+	}
+
+	token, err := newToken()
+	if err != nil {
+		respondWithText(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	err = srv.state.withTx(r.Context(), func(db database.Querier) error {
+		deleteAPITokensByNameParams := database.DeleteAPITokensByNameParams{
+			UserID: user.ID,
+			Name:   greaderTokenName,
+		}
+		err := db.DeleteAPITokensByName(r.Context(), deleteAPITokensByNameParams)
+		if err != nil {
+			return err
+		}
+
+		createAPITokenParams := database.CreateAPITokenParams{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			Name:      greaderTokenName,
+			TokenHash: hashToken(token),
+			CreatedAt: time.Now().UTC(),
+		}
+		_, err = db.CreateAPIToken(r.Context(), createAPITokenParams)
+		return err
+	})
+	if err != nil {
+		log.Printf("failed to create api token: %v", err)
+		respondWithText(w, http.StatusInternalServerError, "failed to create api token")
+		return
+	}
+	respondWithText(w, http.StatusOK, fmt.Sprintf("SID=%s\nLSID=%s\nAuth=%s\n", token, token, token))
+}
+
+// handleGReaderToken returns the token clients send back with every write.
+// Requests are authenticated by header rather than cookie, so it is not
+// checked.
+func (srv *server) handleGReaderToken(w http.ResponseWriter, r *http.Request, user database.User) {
+	token, err := newToken()
+	if err != nil {
+		respondWithText(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondWithText(w, http.StatusOK, token)
+}
+
+func (srv *server) handleGReaderUserInfo(w http.ResponseWriter, r *http.Request, user database.User) {
+	type response struct {
+		UserID        string `json:"userId"`
+		UserName      string `json:"userName"`
+		UserProfileID string `json:"userProfileId"`
+		UserEmail     string `json:"userEmail"`
+	}
+	respondWithJSON(w, http.StatusOK, response{
+		UserID:        user.ID.String(),
+		UserName:      user.Name,
+		UserProfileID: user.ID.String(),
+	})
+}
+
+func (srv *server) handleGReaderSubscriptions(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := srv.state.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to get follows")
+		return
+	}
+
+	type response struct {
+		Subscriptions []greaderSubscription `json:"subscriptions"`
+	}
+	subscriptions := make([]greaderSubscription, 0, len(follows))
+	for _, follow := range follows {
+		categories := []greaderCategory{}
+		if follow.Folder.Valid {
+			categories = append(categories, greaderCategory{
+				ID:    greaderLabelPrefix + follow.Folder.String,
+				Label: follow.Folder.String,
+			})
+		}
+		subscriptions = append(subscriptions, greaderSubscription{
+			ID:         greaderFeedPrefix + follow.FeedUrl,
+			Title:      follow.FeedName,
+			Categories: categories,
+			URL:        follow.FeedUrl,
+			HTMLURL:    follow.FeedUrl,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response{Subscriptions: subscriptions})
+}
+
+func (srv *server) handleGReaderEditSubscription(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedURL, found := strings.CutPrefix(r.FormValue("s"), greaderFeedPrefix)
+	if !found || feedURL == "" {
+		respondWithText(w, http.StatusBadRequest, "invalid stream id")
+		return
+	}
+
+	switch r.FormValue("ac") {
+	case "subscribe":
+		if !isWebURL(feedURL) {
+			respondWithText(w, http.StatusBadRequest, "feed must be an absolute http or https URL")
+			return
+		}
+		name := r.FormValue("t")
+		if name == "" {
+			name = feedURL
+		}
+		if !srv.greaderAddFeed(w, r, user, name, feedURL) {
+			return
+		}
+		if !srv.greaderEditFolder(w, r, user, feedURL) {
+			return
+		}
+	case "unsubscribe":
+		unfollowArgs := database.DeleteFeedFollowByUserAndFeedURLParams{
+			UserID: user.ID,
+			Url:    feedURL,
+		}
+		err := srv.state.db.DeleteFeedFollowByUserAndFeedURL(r.Context(), unfollowArgs)
+		if err != nil {
+			respondWithText(w, http.StatusInternalServerError, "failed to unfollow feed")
+			return
+		}
+	case "edit":
+		// Feed titles are shared between users, so only the folder can change.
+		if !srv.greaderEditFolder(w, r, user, feedURL) {
+			return
+		}
+	default:
+		respondWithText(w, http.StatusBadRequest, "unknown action")
+		return
+	}
+	respondWithText(w, http.StatusOK, "OK")
+}
+
+// greaderAddFeed adds the feed if it is new and subscribes the user to it.
+// It reports whether it did, having answered the request if it did not.
+func (srv *server) greaderAddFeed(w http.ResponseWriter, r *http.Request, user database.User, name, feedURL string) bool {
+	result, err := addFeed(r.Context(), srv.state, user, name, feedURL)
+	if storage.IsUniqueViolation(err) {
+		// Someone else added the same feed at the same time.
+		respondWithText(w, http.StatusConflict, "feed already exists, try again to subscribe")
+		return false
+	}
+	if err != nil {
+		log.Printf("failed to add feed %s: %v", feedURL, err)
+		respondWithText(w, http.StatusInternalServerError, "failed to add feed")
+		return false
+	}
+	if result.AlreadyFollowing {
+		respondWithText(w, http.StatusConflict, "already subscribed to this feed")
+		return false
+	}
+	return true
+}
+
+// greaderEditFolder moves the feed into the label in a, or out of its folder
+// when the label in r is removed. It reports whether that worked, having
+// answered the request if it did not.
+func (srv *server) greaderEditFolder(w http.ResponseWriter, r *http.Request, user database.User, feedURL string) bool {
+	folder, found := strings.CutPrefix(normalizeGReaderStream(r.FormValue("a")), greaderLabelPrefix)
+	if !found {
+		if !strings.HasPrefix(normalizeGReaderStream(r.FormValue("r")), greaderLabelPrefix) {
+			return true
+		}
+		folder = ""
+	}
+
+	n, err := setFolder(r.Context(), srv.state.db, user, feedURL, folder)
+	if err != nil {
+		log.Printf("failed to set folder of %s: %v", feedURL, err)
+		respondWithText(w, http.StatusInternalServerError, "failed to set folder")
+		return false
+	}
+	if n == 0 {
+		respondWithText(w, http.StatusNotFound, "not subscribed to this feed")
+		return false
+	}
+	return true
+}
+
+// handleGReaderQuickAdd subscribes to a feed by URL, naming it after the
+// feed's own title.
+func (srv *server) handleGReaderQuickAdd(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedURL := strings.TrimPrefix(r.FormValue("quickadd"), greaderFeedPrefix)
+	if feedURL == "" {
+		respondWithText(w, http.StatusBadRequest, "quickadd is required")
+		return
+	}
+	if !isWebURL(feedURL) {
+		respondWithText(w, http.StatusBadRequest, "quickadd must be an absolute http or https URL")
+		return
+	}
+
+	type response struct {
+		NumResults int    `json:"numResults"`
+		Query      string `json:"query"`
+		StreamID   string `json:"streamId,omitempty"`
+		Error      string `json:"error,omitempty"`
+	}
+
+	name := feedURL
+	_, err := srv.state.db.GetFeedByURL(r.Context(), feedURL)
+	if err == sql.ErrNoRows {
+		feed, err := rssFeed.FetchFeed(r.Context(), feedURL)
+		if err != nil {
+			log.Printf("failed to fetch feed %s: %v", feedURL, err)
+			respondWithJSON(w, http.StatusOK, response{Query: feedURL, Error: "failed to fetch feed"})
+			return
+		}
+		if feed.Channel.Title != "" {
+			name = feed.Channel.Title
+		}
+	} else if err != nil {
+		respondWithText(w, http.StatusInternalServerError, "failed to check feed existence")
+		return
+	}
+
+	if !srv.greaderAddFeed(w, r, user, name, feedURL) {
+		return
+	}
+	respondWithJSON(w, http.StatusOK, response{
+		NumResults: 1,
+		Query:      feedURL,
+		StreamID:   greaderFeedPrefix + feedURL,
+	})
+}
+
+func (srv *server) handleGReaderTags(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := srv.state.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to get follows")
+		return
+	}
+
+	type tag struct {
+		ID   string `json:"id"`
+		Type string `json:"type,omitempty"`
+	}
+	type response struct {
+		Tags []tag `json:"tags"`
+	}
+	tags := []tag{{ID: greaderStreamStarred}}
+	seen := map[string]bool{}
+	for _, follow := range follows {
+		if !follow.Folder.Valid || seen[follow.Folder.String] {
+			continue
+		}
+		seen[follow.Folder.String] = true
+		tags = append(tags, tag{ID: greaderLabelPrefix + follow.Folder.String, Type: "folder"})
+	}
+	respondWithJSON(w, http.StatusOK, response{Tags: tags})
+}
+
+func (srv *server) handleGReaderUnreadCount(w http.ResponseWriter, r *http.Request, user database.User) {
+	counts, err := srv.state.db.GetUnreadCountsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to get unread counts")
+		return
+	}
+
+	type unreadCount struct {
+		ID                      string `json:"id"`
+		Count                   int64  `json:"count"`
+		NewestItemTimestampUsec string `json:"newestItemTimestampUsec"`
+	}
+	type response struct {
+		Max          int           `json:"max"`
+		UnreadCounts []unreadCount `json:"unreadcounts"`
+	}
+
+	// Folders and the reading list sum up the counts of their feeds.
+	type total struct {
+		count  int64
+		newest time.Time
+	}
+	totals := map[string]*total{}
+	var totalIDs []string
+	addToTotal := func(id string, count database.GetUnreadCountsForUserRow) {
+		t, ok := totals[id]
+		if !ok {
+			t = &total{}
+			totals[id] = t
+			totalIDs = append(totalIDs, id)
+		}
+		t.count += count.UnreadCount
+		if count.NewestCreatedAt.After(t.newest) {
+			t.newest = count.NewestCreatedAt
+		}
+	}
+
+	unreadCounts := make([]unreadCount, 0, len(counts)+1)
+	for _, count := range counts {
+		unreadCounts = append(unreadCounts, unreadCount{
+			ID:                      greaderFeedPrefix + count.FeedUrl,
+			Count:                   count.UnreadCount,
+			NewestItemTimestampUsec: strconv.FormatInt(count.NewestCreatedAt.UnixMicro(), 10),
+		})
+		addToTotal(greaderStreamReadingList, count)
+		if count.Folder.Valid {
+			addToTotal(greaderLabelPrefix+count.Folder.String, count)
+		}
+	}
+	for _, id := range totalIDs {
+		unreadCounts = append(unreadCounts, unreadCount{
+			ID:                      id,
+			Count:                   totals[id].count,
+			NewestItemTimestampUsec: strconv.FormatInt(totals[id].newest.UnixMicro(), 10),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response{Max: greaderMaxItems, UnreadCounts: unreadCounts})
+}
+
+// greaderFilter maps a stream id and the n, c, xt and it parameters onto
+// the browse filters. The continuation is simply the offset of the next page.
+func greaderFilter(r *http.Request, streamID string) (postFilter, error) {
+	filter := postFilter{Limit: apiDefaultPostLimit}
+
+	switch streamID = normalizeGReaderStream(streamID); {
+	case streamID == greaderStreamReadingList:
+	case streamID == greaderStreamStarred:
+		filter.StarredOnly = true
+	case strings.HasPrefix(streamID, greaderFeedPrefix):
+		filter.FeedURL = strings.TrimPrefix(streamID, greaderFeedPrefix)
+	case strings.HasPrefix(streamID, greaderLabelPrefix):
+		filter.Folder = strings.TrimPrefix(streamID, greaderLabelPrefix)
+	default:
+		return postFilter{}, fmt.Errorf("unsupported stream %q", streamID)
+	}
+
+	var err error
+	if value := r.FormValue("n"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil || filter.Limit < 1 {
+			return postFilter{}, invalidQueryError("n")
+		}
+		filter.Limit = min(filter.Limit, greaderMaxItems)
+	}
+	if value := r.FormValue("c"); value != "" {
+		filter.Offset, err = strconv.Atoi(value)
+		if err != nil || filter.Offset < 0 {
+			return postFilter{}, invalidQueryError("c")
+		}
+	}
+	if normalizeGReaderStream(r.FormValue("xt")) == greaderStreamRead {
+		filter.UnreadOnly = true
+	}
+	if normalizeGReaderStream(r.FormValue("it")) == greaderStreamStarred {
+		filter.StarredOnly = true
+	}
+	return filter, nil
+}
+
+// greaderPosts returns a page of posts for the stream and the continuation
+// for the next page, which is empty on the last one.
+func (srv *server) greaderPosts(r *http.Request, user database.User, streamID string) ([]database.GetPostsForUserRow, string, error) {
+	filter, err := greaderFilter(r, streamID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	posts, err := srv.state.db.GetPostsForUser(r.Context(), filter.params(user.ID))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	continuation := ""
+	if len(posts) == filter.Limit {
+		continuation = strconv.Itoa(filter.Offset + filter.Limit)
+	}
+	return posts, continuation, nil
+}
+
+func (srv *server) handleGReaderStreamContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	streamID := r.PathValue("stream")
+	if streamID == "" {
+		streamID = r.FormValue("s")
+	}
+
+	posts, continuation, err := srv.greaderPosts(r, user, streamID)
+	if err != nil {
+		respondWithText(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	type response struct {
+		ID           string        `json:"id"`
+		Updated      int64         `json:"updated"`
+		Items        []greaderItem `json:"items"`
+		Continuation string        `json:"continuation,omitempty"`
+	}
+	items := make([]greaderItem, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, newGReaderItem(post))
+	}
+	respondWithJSON(w, http.StatusOK, response{
+		ID:           streamID,
+		Updated:      time.Now().UTC().Unix(),
+		Items:        items,
+		Continuation: continuation,
+	})
+}
+
+func (srv *server) handleGReaderItemIDs(w http.ResponseWriter, r *http.Request, user database.User) {
+	posts, continuation, err := srv.greaderPosts(r, user, r.FormValue("s"))
+	if err != nil {
+		respondWithText(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	type response struct {
+		ItemRefs     []greaderItemRef `json:"itemRefs"`
+		Continuation string           `json:"continuation,omitempty"`
+	}
+	itemRefs := make([]greaderItemRef, 0, len(posts))
+	for _, post := range posts {
+		itemRefs = append(itemRefs, greaderItemRef{
+			ID:              greaderItemID(post.ID),
+			DirectStreamIDs: []string{},
+			TimestampUsec:   strconv.FormatInt(post.CreatedAt.UnixMicro(), 10),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response{ItemRefs: itemRefs, Continuation: continuation})
+}
+
+// greaderPostsByID looks up every item id in the i form values. Unknown ids,
+// and those of feeds the user does not follow, are skipped, as clients may
+// hold on to ids of posts that are gone.
+func greaderPostsByID(ctx context.Context, db database.Querier, r *http.Request, user database.User) ([]database.GetPostsForUserRow, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []database.GetPostsForUserRow
+	for _, value := range r.Form["i"] {
+		minID, maxID, err := parseGReaderItemID(value)
+		if err != nil {
+			return nil, err
+		}
+
+		getPostParams := database.GetPostForUserByIDRangeParams{
+			UserID: user.ID,
+			MinID:  minID,
+			MaxID:  maxID,
+		}
+		post, err := db.GetPostForUserByIDRange(ctx, getPostParams)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get post: %w", err)
+		}
+		posts = append(posts, database.GetPostsForUserRow(post))
+	}
+	return posts, nil
+}
+
+func (srv *server) handleGReaderItemContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	posts, err := greaderPostsByID(r.Context(), srv.state.db, r, user)
+	if err != nil {
+		respondWithText(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	type response struct {
+		ID      string        `json:"id"`
+		Updated int64         `json:"updated"`
+		Items   []greaderItem `json:"items"`
+	}
+	items := make([]greaderItem, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, newGReaderItem(post))
+	}
+	respondWithJSON(w, http.StatusOK, response{
+		ID:      greaderStreamReadingList,
+		Updated: time.Now().UTC().Unix(),
+		Items:   items,
+	})
+}
+
+// handleGReaderEditTag adds the tags in a and removes the tags in r for
+// every item in i. Each may be given more than once. Only the read,
+// kept-unread and starred states are stored.
+func (srv *server) handleGReaderEditTag(w http.ResponseWriter, r *http.Request, user database.User) {
+	err := srv.state.withTx(r.Context(), func(db database.Querier) error {
+		posts, err := greaderPostsByID(r.Context(), db, r, user)
+		if err != nil {
+			return err
+		}
+
+		for _, post := range posts {
+			for _, tag := range r.Form["a"] {
+				err = greaderSetTag(r.Context(), db, user, post, normalizeGReaderStream(tag), true)
+				if err != nil {
+					return err
+				}
+			}
+			for _, tag := range r.Form["r"] {
+				err = greaderSetTag(r.Context(), db, user, post, normalizeGReaderStream(tag), false)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		respondWithText(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondWithText(w, http.StatusOK, "OK")
+}
+
+// greaderSetTag adds or removes a state tag of post. Other tags are ignored.
+func greaderSetTag(ctx context.Context, db database.Querier, user database.User, post database.GetPostsForUserRow, tag string, value bool) error {
+	switch tag {
+	case greaderStreamRead:
+		return setPostRead(ctx, db, user.ID, post.ID, value)
+	case greaderStreamKeptUnread:
+		return setPostRead(ctx, db, user.ID, post.ID, !value)
+	case greaderStreamStarred:
+		return setPostStarred(ctx, db, user.ID, post.ID, value)
+	}
+	return nil
+}
+
+// handleGReaderMarkAllAsRead marks the stream in s as read, up to the
+// optional ts timestamp in microseconds.
+func (srv *server) handleGReaderMarkAllAsRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	markAllPostsReadParams := database.MarkAllPostsReadParams{
+		ReadAt:    time.Now().UTC(),
+		UserID:    user.ID,
+		OlderThan: time.Now().UTC(),
+	}
+
+	switch streamID := normalizeGReaderStream(r.FormValue("s")); {
+	case streamID == greaderStreamReadingList:
+	case strings.HasPrefix(streamID, greaderFeedPrefix):
+		markAllPostsReadParams.FeedUrl = sql.NullString{String: strings.TrimPrefix(streamID, greaderFeedPrefix), Valid: true}
+	case strings.HasPrefix(streamID, greaderLabelPrefix):
+		markAllPostsReadParams.Folder = sql.NullString{String: strings.TrimPrefix(streamID, greaderLabelPrefix), Valid: true}
+	default:
+		respondWithText(w, http.StatusBadRequest, fmt.Sprintf("unsupported stream %q", streamID))
+		return
+	}
+
+	if value := r.FormValue("ts"); value != "" {
+		usec, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			respondWithText(w, http.StatusBadRequest, invalidQueryError("ts").Error())
+			return
+		}
+		markAllPostsReadParams.OlderThan = time.UnixMicro(usec).UTC()
+	}
+
+	err := srv.state.db.MarkAllPostsRead(r.Context(), markAllPostsReadParams)
+	if err != nil {
+		respondWithText(w, http.StatusInternalServerError, "failed to mark posts as read")
+		return
+	}
+	respondWithText(w, http.StatusOK, "OK")
+}
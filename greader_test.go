@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseGReaderItemID(t *testing.T) {
+	id := uuid.MustParse("9f3c2a10-4b5d-4e6f-8a7b-0c1d2e3f4a5b")
+	wantMin := uuid.MustParse("9f3c2a10-4b5d-4e6f-0000-000000000000")
+	wantMax := uuid.MustParse("9f3c2a10-4b5d-4e6f-ffff-ffffffffffff")
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"short", greaderItemID(id)},
+		{"long", greaderLongItemID(id)},
+		{"unsigned short", "11474092200105430639"},
+	}
+	for _, tt := range tests {
+		minID, maxID, err := parseGReaderItemID(tt.value)
+		if err != nil {
+			t.Errorf("%s: parseGReaderItemID(%q) returned error: %v", tt.name, tt.value, err)
+			continue
+		}
+		if minID != wantMin || maxID != wantMax {
+			t.Errorf("%s: parseGReaderItemID(%q) = %v, %v, want %v, %v", tt.name, tt.value, minID, maxID, wantMin, wantMax)
+		}
+	}
+
+	for _, value := range []string{"", "abc", greaderItemPrefix + "xyz"} {
+		if _, _, err := parseGReaderItemID(value); err == nil {
+			t.Errorf("parseGReaderItemID(%q) returned no error", value)
+		}
+	}
+}
+
+func TestNormalizeGReaderStream(t *testing.T) {
+	tests := map[string]string{
+		"user/1234/state/com.google/read": "user/-/state/com.google/read",
+		"user/-/label/Tech":               "user/-/label/Tech",
+		"feed/https://example.com/rss":    "feed/https://example.com/rss",
+		"user/1234":                       "user/1234",
+	}
+	for streamID, want := range tests {
+		if got := normalizeGReaderStream(streamID); got != want {
+			t.Errorf("normalizeGReaderStream(%q) = %q, want %q", streamID, got, want)
+		}
+	}
+}
+
+func TestHandleGReaderEditSubscription(t *testing.T) {
+	s := newTestState(t)
+	srv := &server{state: s}
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	createTestFeed(t, s, alice, "https://example.com/followed")
+	createTestFeed(t, s, bob, "https://example.com/other")
+
+	tests := []struct {
+		name string
+		form url.Values
+		want int
+	}{
+		{"not a web url", url.Values{"ac": {"subscribe"}, "s": {"feed/file:///etc/passwd"}}, http.StatusBadRequest},
+		{"relative url", url.Values{"ac": {"subscribe"}, "s": {"feed/example.com/feed"}}, http.StatusBadRequest},
+		{"already subscribed", url.Values{"ac": {"subscribe"}, "s": {"feed/https://example.com/followed"}}, http.StatusConflict},
+		{"subscribe to existing feed", url.Values{"ac": {"subscribe"}, "s": {"feed/https://example.com/other"}, "a": {"user/-/label/News"}}, http.StatusOK},
+		{"edit folder", url.Values{"ac": {"edit"}, "s": {"feed/https://example.com/followed"}, "a": {"user/-/label/Tech"}}, http.StatusOK},
+		{"remove folder", url.Values{"ac": {"edit"}, "s": {"feed/https://example.com/followed"}, "r": {"user/-/label/Tech"}}, http.StatusOK},
+		{"edit feed not subscribed to", url.Values{"ac": {"edit"}, "s": {"feed/https://example.com/unknown"}, "a": {"user/-/label/Tech"}}, http.StatusNotFound},
+		{"unknown action", url.Values{"ac": {"rename"}, "s": {"feed/https://example.com/followed"}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/reader/api/0/subscription/edit", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			srv.handleGReaderEditSubscription(w, r, alice)
+			if w.Code != tt.want {
+				t.Errorf("status = %d (%s), want %d", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	folders := map[string]string{}
+	for _, follow := range follows {
+		folders[follow.FeedUrl] = follow.Folder.String
+	}
+	if len(folders) != 2 || folders["https://example.com/other"] != "News" || folders["https://example.com/followed"] != "" {
+		t.Errorf("folders = %v", folders)
+	}
+}
+
+func TestHandleGReaderQuickAdd(t *testing.T) {
+	s := newTestState(t)
+	srv := &server{state: s}
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	createTestFeed(t, s, alice, "https://example.com/followed")
+	createTestFeed(t, s, bob, "https://example.com/other")
+
+	tests := []struct {
+		name     string
+		quickadd string
+		want     int
+	}{
+		{"missing", "", http.StatusBadRequest},
+		{"not a web url", "feed/javascript:alert(1)", http.StatusBadRequest},
+		{"already subscribed", "feed/https://example.com/followed", http.StatusConflict},
+		{"existing feed", "https://example.com/other", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"quickadd": {tt.quickadd}}
+			r := httptest.NewRequest(http.MethodPost, "/reader/api/0/subscription/quickadd", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			srv.handleGReaderQuickAdd(w, r, alice)
+			if w.Code != tt.want {
+				t.Errorf("status = %d (%s), want %d", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
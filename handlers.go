@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Romasav/gator/internal/database"
@@ -19,7 +20,7 @@ func handlerLogin(s *state, cmd command) error {
 	}
 	username := cmd.Arguments[0]
 
-	_, err := s.db.GetUser(context.Background(), username)
+	user, err := s.db.GetUser(context.Background(), username)
 	if err == sql.ErrNoRows {
 		return errors.New("the user dose not exists")
 	}
@@ -27,7 +28,18 @@ func handlerLogin(s *state, cmd command) error {
 		return fmt.Errorf("failed to check user existence: %w", err)
 	}
 
-	err = s.config.SetUpUser(username)
+	if user.HashedPassword.Valid {
+		password, err := readPassword("Password: ")
+		if err != nil {
+			return err
+		}
+		err = checkPassword(user, password)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = startSession(s, user)
 	if err != nil {
 		return fmt.Errorf("failed to set up user: %w", err)
 	}
@@ -35,18 +47,33 @@ func handlerLogin(s *state, cmd command) error {
 	return nil
 }
 
+func handlerLogout(s *state, cmd command) error {
+	if len(cmd.Arguments) != 0 {
+		return fmt.Errorf("logout dosent require any arguments, found %v arguments", cmd.Arguments)
+	}
+	if s.config.SessionToken == "" {
+		return errNotLoggedIn
+	}
+
+	err := s.db.DeleteSession(context.Background(), hashToken(s.config.SessionToken))
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	err = s.config.SetSession("")
+	if err != nil {
+		return fmt.Errorf("failed to clear session: %w", err)
+	}
+	fmt.Println("You have been logged out.")
+	return nil
+}
+
 func handlerRegister(s *state, cmd command) error {
 	if len(cmd.Arguments) != 1 {
 		return fmt.Errorf("register requires exactly 1 argument (username), found %v arguments", cmd.Arguments)
 	}
 	username := cmd.Arguments[0]
 
-	createUserParams := database.CreateUserParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name:      username,
-	}
 	_, err := s.db.GetUser(context.Background(), username)
 	if err == nil {
 		return errors.New("the user already exists")
@@ -55,12 +82,24 @@ func handlerRegister(s *state, cmd command) error {
 		return fmt.Errorf("failed to check user existence: %w", err)
 	}
 
+	hashedPassword, err := promptNewPassword()
+	if err != nil {
+		return err
+	}
+
+	createUserParams := database.CreateUserParams{
+		ID:             uuid.New(),
+		CreatedAt:      time.Now().UTC(),
+		UpdatedAt:      time.Now().UTC(),
+		Name:           username,
+		HashedPassword: hashedPassword,
+	}
 	user, err := s.db.CreateUser(context.Background(), createUserParams)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 
-	err = s.config.SetUpUser(user.Name)
+	err = startSession(s, user)
 	if err != nil {
 		return fmt.Errorf("failed to set up user: %w", err)
 	}
@@ -69,16 +108,149 @@ func handlerRegister(s *state, cmd command) error {
 	return nil
 }
 
-func handlerReset(s *state, cmd command) error {
+func handlerPassword(s *state, cmd command, user database.User) error {
 	if len(cmd.Arguments) != 0 {
-		return fmt.Errorf("reset dosent require any arguments, found %v arguments", cmd.Arguments)
+		return fmt.Errorf("password dosent require any arguments, found %v arguments", cmd.Arguments)
 	}
 
-	err := s.db.DeleteAllUsers(context.Background())
+	if user.HashedPassword.Valid {
+		current, err := readPassword("Current password: ")
+		if err != nil {
+			return err
+		}
+		err = checkPassword(user, current)
+		if err != nil {
+			return err
+		}
+	}
+
+	hashedPassword, err := promptNewPassword()
+	if err != nil {
+		return err
+	}
+
+	updateUserPasswordParams := database.UpdateUserPasswordParams{
+		ID:             user.ID,
+		HashedPassword: hashedPassword,
+		UpdatedAt:      time.Now().UTC(),
+	}
+	_, err = s.db.UpdateUserPassword(context.Background(), updateUserPasswordParams)
 	if err != nil {
-		return fmt.Errorf("failed to delete all users: %w", err)
+		return fmt.Errorf("failed to update password: %w", err)
 	}
 
+	if hashedPassword.Valid {
+		fmt.Println("Your password has been changed.")
+	} else {
+		fmt.Println("Your password has been removed.")
+	}
+	return nil
+}
+
+func handlerReset(s *state, cmd command, user database.User) error {
+	args, yes := popFlag(cmd.Arguments, "--yes")
+	if len(args) != 0 {
+		return fmt.Errorf("reset only accepts --yes, found %v arguments", args)
+	}
+
+	if !yes {
+		ok, err := confirm("This deletes every user, feed, follow and post. Continue?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Reset cancelled.")
+			return nil
+		}
+	}
+
+	err := s.withTx(context.Background(), func(db database.Querier) error {
+		// Feeds no longer go away with their owner, so clear them explicitly.
+		err := db.DeleteAllFeeds(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to delete all feeds: %w", err)
+		}
+
+		err = db.DeleteAllUsers(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to delete all users: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("The database has been reset.")
+	return nil
+}
+
+func handlerAdmin(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) != 2 {
+		return fmt.Errorf("admin requires exactly 2 arguments (grant or revoke, username), found %v arguments", len(cmd.Arguments))
+	}
+	action := cmd.Arguments[0]
+	username := cmd.Arguments[1]
+
+	if action != "grant" && action != "revoke" {
+		return fmt.Errorf("unknown admin subcommand %v, expected grant or revoke", action)
+	}
+	if action == "revoke" && username == user.Name {
+		return errors.New("you cannot revoke your own admin rights")
+	}
+
+	target, err := s.db.GetUser(context.Background(), username)
+	if err == sql.ErrNoRows {
+		return errors.New("the user dose not exists")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	err = s.withTx(context.Background(), func(db database.Querier) error {
+		if action == "revoke" {
+			err := requireOtherAdmin(context.Background(), db, target)
+			if err != nil {
+				return err
+			}
+		}
+
+		setUserAdminParams := database.SetUserAdminParams{
+			ID:        target.ID,
+			IsAdmin:   action == "grant",
+			UpdatedAt: time.Now().UTC(),
+		}
+		_, err := db.SetUserAdmin(context.Background(), setUserAdminParams)
+		if err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if action == "grant" {
+		fmt.Printf("User %s is now an admin\n", target.Name)
+	} else {
+		fmt.Printf("User %s is no longer an admin\n", target.Name)
+	}
+	return nil
+}
+
+// requireOtherAdmin fails if user is the only admin, as taking their
+// rights away would leave nobody to manage the other users.
+func requireOtherAdmin(ctx context.Context, db database.Querier, user database.User) error {
+	if !user.IsAdmin {
+		return nil
+	}
+	count, err := db.CountOtherAdmins(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count admins: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%s is the last admin, grant admin rights to another user first", user.Name)
+	}
 	return nil
 }
 
@@ -92,9 +264,16 @@ func handlerUsers(s *state, cmd command) error {
 		return fmt.Errorf("failed to get all users: %w", err)
 	}
 
+	// Listing users does not require a login, so a missing session just
+	// means nobody is marked as current.
+	current, _ := currentUser(s)
+
 	for _, user := range users {
 		fmt.Print(user.Name)
-		if user.Name == s.config.Username {
+		if user.IsAdmin {
+			fmt.Print(" (admin)")
+		}
+		if user.ID == current.ID {
 			fmt.Print(" (current)")
 		}
 		fmt.Println()
@@ -117,7 +296,15 @@ func handlerAggregator(s *state, cmd command) error {
 
 	ticker := time.NewTicker(timeBetweenRequests)
 
+	var lastPruned time.Time
 	for {
+		if time.Since(lastPruned) >= time.Hour {
+			_, err := pruneWebhookDeliveries(s)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+			lastPruned = time.Now()
+		}
 		scrapeFeeds(s)
 		<-ticker.C
 	}
@@ -129,56 +316,182 @@ func scrapeFeeds(s *state) error {
 		return fmt.Errorf("failed to get next feed: %w", err)
 	}
 
-	err = s.db.MarkFeedFetched(context.Background(), feed.ID)
+	_, err = scrapeFeed(s, feed, func(format string, args ...interface{}) {
+		fmt.Printf(format+"\n", args...)
+	})
+	return err
+}
+
+// scrapeFeed fetches feed and stores the posts that are new, returning how
+// many there were. Items that can not be stored are reported through warn
+// and skipped. New posts get their full article if the feed asks for it,
+// go through the followers' filter rules and are then sent to the matching
+// webhooks.
+func scrapeFeed(s *state, feed database.Feed, warn func(format string, args ...interface{})) (int, error) {
+	markFeedFetchedParams := database.MarkFeedFetchedParams{
+		FetchedAt: time.Now().UTC(),
+		ID:        feed.ID,
+	}
+	err := s.db.MarkFeedFetched(context.Background(), markFeedFetchedParams)
 	if err != nil {
-		return fmt.Errorf("failed to mark feed as fetched: %w", err)
+		return 0, fmt.Errorf("failed to mark feed as fetched: %w", err)
 	}
 
 	rssFeed, err := rssFeed.FetchFeed(context.Background(), feed.Url)
 	if err != nil {
-		return fmt.Errorf("failed to fetch feed: %w", err)
+		return 0, fmt.Errorf("failed to fetch feed: %w", err)
 	}
 
+	setFeedParseWarningParams := database.SetFeedParseWarningParams{
+		ID:               feed.ID,
+		LastParseWarning: sql.NullString{String: rssFeed.ParseWarning, Valid: rssFeed.ParseWarning != ""},
+	}
+	err = s.db.SetFeedParseWarning(context.Background(), setFeedParseWarningParams)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record parse warning: %w", err)
+	}
+	if rssFeed.ParseWarning != "" {
+		warn("Feed %s: %s", feed.Name, rssFeed.ParseWarning)
+	}
+
+	var created []database.Post
 	for _, item := range rssFeed.Channel.Items {
-		publishedAt, err := parsePublishedDate(item.PubDate)
-		if err != nil {
-			fmt.Println("Error parsing published date:", err)
+		// Posts are told apart by their link, so an item without one can not
+		// be stored.
+		if item.Link == "" {
+			warn("Skipping '%s' of feed %s, it has no link", item.Title, feed.Name)
 			continue
 		}
 
+		// Items whose date is missing or can not be read are kept, dated
+		// when they were first seen.
+		publishedAt, dateErr := item.PublishedTime()
+		if dateErr != nil {
+			publishedAt = time.Now()
+		}
+
 		newPost := database.CreatePostParams{
 			ID:          uuid.New(),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			CreatedAt:   time.Now().UTC(),
+			UpdatedAt:   time.Now().UTC(),
 			Title:       item.Title,
 			Url:         item.Link,
 			Description: sql.NullString{String: item.Description, Valid: item.Description != ""},
-			PublishedAt: sql.NullTime{Time: publishedAt, Valid: publishedAt != time.Time{}},
+			PublishedAt: sql.NullTime{Time: publishedAt.UTC(), Valid: true},
 			FeedID:      feed.ID,
+			Content:     sql.NullString{String: item.Content, Valid: strings.TrimSpace(item.Content) != ""},
+			Author:      sql.NullString{String: item.AuthorName(), Valid: item.AuthorName() != ""},
+			Categories:  sql.NullString{String: strings.Join(item.CategoryNames(), "\n"), Valid: len(item.CategoryNames()) > 0},
 		}
 
-		_, err = s.db.CreatePost(context.Background(), newPost)
+		post, err := s.db.CreatePost(context.Background(), newPost)
+		if err == sql.ErrNoRows {
+			continue
+		}
 		if err != nil {
-			if err != sql.ErrNoRows {
-				fmt.Println("Error saving post:", err)
-			}
+			warn("Error saving post: %v", err)
+			continue
 		}
+		if dateErr != nil && strings.TrimSpace(item.PubDate+item.Date) != "" {
+			warn("Error parsing published date of '%s', using the time it was first seen: %v", post.Title, dateErr)
+		}
+		createEnclosures(s, post, item.Enclosures, warn)
+		createEpisode(s, post, item, rssFeed.Channel.Image.Href, warn)
+		created = append(created, post)
 	}
-	return nil
-}
 
-func parsePublishedDate(pubDate string) (time.Time, error) {
-	formats := []string{
-		time.RFC1123Z,
-		time.RFC1123,
-		time.RFC3339,
+	if feed.FetchFullContent {
+		fetchArticles(s, created, warn)
 	}
-	for _, format := range formats {
-		if t, err := time.Parse(format, pubDate); err == nil {
-			return t, nil
+	applyFilterRules(s, feed, created, warn)
+	dispatchWebhooks(s, feed, created, warn)
+	return len(created), nil
+}
+
+// createEnclosures stores the media files attached to an item. Enclosures
+// without a URL are skipped, and a length that is not a number is dropped.
+func createEnclosures(s *state, post database.Post, enclosures []rssFeed.RSSEnclosure, warn func(format string, args ...interface{})) {
+	for _, enclosure := range enclosures {
+		if strings.TrimSpace(enclosure.URL) == "" {
+			continue
+		}
+		createPostEnclosureParams := database.CreatePostEnclosureParams{
+			ID:        uuid.New(),
+			PostID:    post.ID,
+			Url:       strings.TrimSpace(enclosure.URL),
+			MediaType: strings.TrimSpace(enclosure.Type),
+			CreatedAt: time.Now().UTC(),
+		}
+		if length, err := strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64); err == nil && length > 0 {
+			createPostEnclosureParams.Length = sql.NullInt64{Int64: length, Valid: true}
+		}
+		err := s.db.CreatePostEnclosure(context.Background(), createPostEnclosureParams)
+		if err != nil {
+			warn("Error saving enclosure: %v", err)
 		}
 	}
-	return time.Time{}, fmt.Errorf("unsupported date format: %s", pubDate)
+}
+
+type addFeedResult struct {
+	Feed             database.Feed
+	Created          bool
+	AlreadyFollowing bool
+}
+
+// addFeed creates the feed at feedURL unless it already exists and makes
+// user follow it, all in one transaction.
+func addFeed(ctx context.Context, s *state, user database.User, name, feedURL string) (addFeedResult, error) {
+	var result addFeedResult
+	err := s.withTx(ctx, func(db database.Querier) error {
+		var err error
+		result.Feed, err = db.GetFeedByURL(ctx, feedURL)
+		if err == sql.ErrNoRows {
+			createFeedParams := database.CreateFeedParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now().UTC(),
+				UpdatedAt: time.Now().UTC(),
+				Name:      name,
+				Url:       feedURL,
+				UserID:    uuid.NullUUID{UUID: user.ID, Valid: true},
+			}
+
+			result.Feed, err = db.CreateFeed(ctx, createFeedParams)
+			if err != nil {
+				return fmt.Errorf("failed to create a feed: %w", err)
+			}
+			result.Created = true
+		} else if err != nil {
+			return fmt.Errorf("failed to check feed existence: %w", err)
+		}
+
+		getFeedFollowParams := database.GetFeedFollowParams{
+			UserID: user.ID,
+			FeedID: result.Feed.ID,
+		}
+		_, err = db.GetFeedFollow(ctx, getFeedFollowParams)
+		if err == nil {
+			result.AlreadyFollowing = true
+			return nil
+		}
+		if err != sql.ErrNoRows {
+			return fmt.Errorf("failed to check feed follow existence: %w", err)
+		}
+
+		createFeedFollowParams := database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			UserID:    user.ID,
+			FeedID:    result.Feed.ID,
+		}
+
+		_, err = db.CreateFeedFollow(ctx, createFeedFollowParams)
+		if err != nil {
+			return fmt.Errorf("failed to create a new feed follow: %w", err)
+		}
+		return nil
+	})
+	return result, err
 }
 
 func handlerCreateFeed(s *state, cmd command, user database.User) error {
@@ -188,44 +501,73 @@ func handlerCreateFeed(s *state, cmd command, user database.User) error {
 	nameFeed := cmd.Arguments[0]
 	urlFeed := cmd.Arguments[1]
 
-	createFeedParams := database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name:      nameFeed,
-		Url:       urlFeed,
-		UserID:    user.ID,
-	}
-
-	feed, err := s.db.CreateFeed(context.Background(), createFeedParams)
+	result, err := addFeed(context.Background(), s, user, nameFeed, urlFeed)
 	if err != nil {
-		return fmt.Errorf("failed to create a feed: %w", err)
-	}
-
-	createFeedFollowParams := database.CreateFeedFollowParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		UserID:    user.ID,
-		FeedID:    feed.ID,
+		return err
 	}
+	feed := result.Feed
 
-	_, err = s.db.CreateFeedFollow(context.Background(), createFeedFollowParams)
-	if err != nil {
-		return fmt.Errorf("failed to create a new feed follow: %w", err)
+	if !result.Created {
+		if result.AlreadyFollowing {
+			fmt.Printf("The feed %s already exists and user %s is already following it\n", feed.Url, user.Name)
+		} else {
+			fmt.Printf("The feed %s already exists, user %s is now following it\n", feed.Url, user.Name)
+		}
+		return nil
 	}
 
 	fmt.Println("New Feed Record:")
 	fmt.Printf("ID:        %s\n", feed.ID.String())
 	fmt.Printf("Name:      %s\n", feed.Name)
 	fmt.Printf("URL:       %s\n", feed.Url)
-	fmt.Printf("User ID:   %s\n", feed.UserID.String())
-	fmt.Printf("CreatedAt: %s\n", feed.CreatedAt.Format(time.RFC3339))
-	fmt.Printf("UpdatedAt: %s\n", feed.UpdatedAt.Format(time.RFC3339))
+	fmt.Printf("User ID:   %s\n", feed.UserID.UUID.String())
+	fmt.Printf("CreatedAt: %s\n", feed.CreatedAt.In(userLocation(user)).Format(time.RFC3339))
+	fmt.Printf("UpdatedAt: %s\n", feed.UpdatedAt.In(userLocation(user)).Format(time.RFC3339))
 
 	return nil
 }
 
+func handlerDeleteFeed(s *state, cmd command, user database.User) error {
+	args, yes := popFlag(cmd.Arguments, "--yes")
+	if len(args) != 1 {
+		return fmt.Errorf("deletefeed requires exactly 1 argument (feed URL), found %v arguments", len(args))
+	}
+	feedURL := args[0]
+
+	if !yes {
+		ok, err := confirm(fmt.Sprintf("This deletes %s with all its posts and follows. Continue?", feedURL))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Deletion cancelled.")
+			return nil
+		}
+	}
+
+	err := s.withTx(context.Background(), func(db database.Querier) error {
+		feed, err := db.GetFeedByURL(context.Background(), feedURL)
+		if err == sql.ErrNoRows {
+			return errors.New("the feed dose not exists")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find feed by url: %w", err)
+		}
+
+		err = db.DeleteFeed(context.Background(), feed.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete feed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Feed '%s' deleted by user '%s'.\n", feedURL, user.Name)
+	return nil
+}
+
 func handlerFeeds(s *state, cmd command) error {
 	if len(cmd.Arguments) != 0 {
 		return fmt.Errorf("feeds dosent require any arguments, found %v arguments", cmd.Arguments)
@@ -237,42 +579,56 @@ func handlerFeeds(s *state, cmd command) error {
 	}
 
 	for index, feed := range feeds {
-		user, err := s.db.GetUserById(context.Background(), feed.UserID)
+		owner, err := feedOwnerName(s.db, feed)
 		if err != nil {
-			return fmt.Errorf("failed to get user by id: %w", err)
+			return err
 		}
 
 		fmt.Printf("%v Feed Record:\n", index+1)
 		fmt.Printf("Name:      %s\n", feed.Name)
 		fmt.Printf("URL:       %s\n", feed.Url)
-		fmt.Printf("User Name: %s\n", user.Name)
+		fmt.Printf("User Name: %s\n", owner)
+		if feed.FetchFullContent {
+			fmt.Println("Content:   full articles")
+		}
+		if feed.LastParseWarning.Valid {
+			fmt.Printf("Warning:   %s\n", feed.LastParseWarning.String)
+		}
 	}
 
 	return nil
 }
 
-func handlerFollow(s *state, cmd command, user database.User) error {
-	if len(cmd.Arguments) != 1 {
-		return fmt.Errorf("follow requires 1 argument, found %v arguments", cmd.Arguments)
-	}
-	feedUrl := cmd.Arguments[0]
-
-	feed, err := s.db.GetFeedByURL(context.Background(), feedUrl)
+func followFeed(ctx context.Context, s *state, user database.User, feedURL string) (database.CreateFeedFollowRow, error) {
+	feed, err := s.db.GetFeedByURL(ctx, feedURL)
 	if err != nil {
-		return fmt.Errorf("failed to find feed by url: %w", err)
+		return database.CreateFeedFollowRow{}, fmt.Errorf("failed to find feed by url: %w", err)
 	}
 
 	createFeedFollowParams := database.CreateFeedFollowParams{
 		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
 		UserID:    user.ID,
 		FeedID:    feed.ID,
 	}
 
-	feedFollow, err := s.db.CreateFeedFollow(context.Background(), createFeedFollowParams)
+	feedFollow, err := s.db.CreateFeedFollow(ctx, createFeedFollowParams)
 	if err != nil {
-		return fmt.Errorf("failed to create a new feed follow: %w", err)
+		return database.CreateFeedFollowRow{}, fmt.Errorf("failed to create a new feed follow: %w", err)
+	}
+	return feedFollow, nil
+}
+
+func handlerFollow(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) != 1 {
+		return fmt.Errorf("follow requires 1 argument, found %v arguments", cmd.Arguments)
+	}
+	feedUrl := cmd.Arguments[0]
+
+	feedFollow, err := followFeed(context.Background(), s, user, feedUrl)
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("User %s is now following the feed %s\n", feedFollow.UserName, feedFollow.FeedName)
@@ -292,6 +648,10 @@ func handlerFollowing(s *state, cmd command, user database.User) error {
 
 	fmt.Println("You are following these feeds:")
 	for _, follow := range feedFollows {
+		if follow.Folder.Valid {
+			fmt.Printf("- %s [%s]\n", follow.FeedName, follow.Folder.String)
+			continue
+		}
 		fmt.Printf("- %s\n", follow.FeedName)
 	}
 
@@ -318,29 +678,202 @@ func handlerUnfollow(s *state, cmd command, user database.User) error {
 	return nil
 }
 
+// postFilter holds the filters shared by browse and the HTTP API.
+type postFilter struct {
+	Limit       int
+	Offset      int
+	UnreadOnly  bool
+	StarredOnly bool
+	FeedURL     string
+	Folder      string
+	// MutedOnly shows the posts hidden by mute rules instead of the others.
+	MutedOnly bool
+	Tag       string
+	// Query is searched for in titles, descriptions and articles, ignoring
+	// case.
+	Query string
+}
+
+func (f postFilter) params(userID uuid.UUID) database.GetPostsForUserParams {
+	return database.GetPostsForUserParams{
+		UserID:      userID,
+		UnreadOnly:  f.UnreadOnly,
+		StarredOnly: f.StarredOnly,
+		FeedUrl:     sql.NullString{String: f.FeedURL, Valid: f.FeedURL != ""},
+		Folder:      sql.NullString{String: f.Folder, Valid: f.Folder != ""},
+		MutedOnly:   f.MutedOnly,
+		Tag:         sql.NullString{String: f.Tag, Valid: f.Tag != ""},
+		Query:       sql.NullString{String: escapeLike(f.Query), Valid: f.Query != ""},
+		Limit:       int32(f.Limit),
+		Offset:      int32(f.Offset),
+	}
+}
+
 func handlerBrowse(s *state, cmd command, user database.User) error {
-	limit := 2
-	if len(cmd.Arguments) > 0 {
-		var err error
-		limit, err = strconv.Atoi(cmd.Arguments[0])
+	filter := postFilter{Limit: 2}
+
+	args, err := popPostFilterFlags(cmd.Arguments, &filter)
+	if err != nil {
+		return err
+	}
+	args, content := popFlag(args, "--content")
+
+	if len(args) > 1 {
+		return fmt.Errorf("browse takes at most 1 argument (limit), found %v arguments", len(args))
+	}
+	if len(args) > 0 {
+		filter.Limit, err = strconv.Atoi(args[0])
 		if err != nil {
-			return fmt.Errorf("invalid limit: %s", cmd.Arguments[0])
+			return fmt.Errorf("invalid limit: %s", args[0])
 		}
 	}
 
-	getPostsParams := database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  int32(limit),
+	posts, err := s.db.GetPostsForUser(context.Background(), filter.params(user.ID))
+	if err != nil {
+		return fmt.Errorf("failed to fetch posts: %w", err)
 	}
 
-	posts, err := s.db.GetPostsForUser(context.Background(), getPostsParams)
+	return printPosts(s, posts, userLocation(user), content)
+}
+
+// popPostFilterFlags reads the filter flags browse and search share.
+func popPostFilterFlags(args []string, filter *postFilter) ([]string, error) {
+	args, feedURL, err := popFlagValue(args, "--feed")
 	if err != nil {
-		return fmt.Errorf("failed to fetch posts: %w", err)
+		return nil, err
+	}
+	filter.FeedURL = feedURL
+	args, filter.Folder, err = popFlagValue(args, "--folder")
+	if err != nil {
+		return nil, err
 	}
+	args, filter.Tag, err = popFlagValue(args, "--tag")
+	if err != nil {
+		return nil, err
+	}
+	args, filter.UnreadOnly = popFlag(args, "--unread")
+	args, filter.StarredOnly = popFlag(args, "--starred")
+	args, filter.MutedOnly = popFlag(args, "--muted")
+	return args, nil
+}
 
+// printPosts lists posts, followed by their text when content is set.
+func printPosts(s *state, posts []database.GetPostsForUserRow, location *time.Location, content bool) error {
 	for _, post := range posts {
-		fmt.Printf("Title: %s\nURL: %s\nPublished: %v\n\n", post.Title, post.Url, post.PublishedAt)
+		published := "unknown"
+		if post.PublishedAt.Valid {
+			published = formatTime(post.PublishedAt.Time, location)
+		}
+		marks := ""
+		if !post.ReadAt.Valid {
+			marks += " [unread]"
+		}
+		if post.StarredAt.Valid {
+			marks += " [starred]"
+		}
+		fmt.Printf("Title: %s%s\nFeed: %s\nURL: %s\nPublished: %s\n", post.Title, marks, post.FeedName, post.Url, published)
+		if post.Author.Valid {
+			fmt.Printf("Author: %s\n", post.Author.String)
+		}
+		if categories := postCategories(post.Categories); len(categories) > 0 {
+			fmt.Printf("Categories: %s\n", strings.Join(categories, ", "))
+		}
+		if tags := postTags(post); len(tags) > 0 {
+			fmt.Printf("Tags: %s\n", strings.Join(tags, ", "))
+		}
+
+		enclosures, err := s.db.GetPostEnclosures(context.Background(), post.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get enclosures: %w", err)
+		}
+		for _, enclosure := range enclosures {
+			fmt.Printf("Enclosure: %s%s\n", enclosure.Url, enclosureDetails(enclosure))
+		}
+
+		if body := postBody(post.Description, post.Content, post.ArticleHtml, textWidth()); content && body != "" {
+			fmt.Printf("\n%s\n", body)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// postCategories splits the categories column, which holds one category
+// per line.
+func postCategories(categories sql.NullString) []string {
+	if !categories.Valid || categories.String == "" {
+		return nil
+	}
+	return strings.Split(categories.String, "\n")
+}
+
+// enclosureDetails describes the type and size of an enclosure, such as
+// " (audio/mpeg, 12.3 MB)", or returns "" if neither is known.
+func enclosureDetails(enclosure database.PostEnclosure) string {
+	var details []string
+	if enclosure.MediaType != "" {
+		details = append(details, enclosure.MediaType)
 	}
+	if enclosure.Length.Valid {
+		details = append(details, formatSize(enclosure.Length.Int64))
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(details, ", ") + ")"
+}
+
+func formatSize(size int64) string {
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "kMGTPE"[exp])
+}
 
+func handlerTimezone(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) > 1 {
+		return fmt.Errorf("timezone takes at most 1 argument (IANA zone name), found %v arguments", len(cmd.Arguments))
+	}
+	if len(cmd.Arguments) == 0 {
+		fmt.Printf("Your display timezone is %s\n", user.Timezone)
+		return nil
+	}
+
+	location, err := time.LoadLocation(cmd.Arguments[0])
+	if err != nil {
+		return fmt.Errorf("unknown timezone %s: %w", cmd.Arguments[0], err)
+	}
+
+	updateUserTimezoneParams := database.UpdateUserTimezoneParams{
+		ID:        user.ID,
+		Timezone:  location.String(),
+		UpdatedAt: time.Now().UTC(),
+	}
+	_, err = s.db.UpdateUserTimezone(context.Background(), updateUserTimezoneParams)
+	if err != nil {
+		return fmt.Errorf("failed to update timezone: %w", err)
+	}
+
+	fmt.Printf("Times will now be shown in %s\n", location.String())
 	return nil
 }
+
+// userLocation returns the display timezone the user picked, falling back
+// to UTC if it can no longer be loaded.
+func userLocation(user database.User) *time.Location {
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+func formatTime(t time.Time, location *time.Location) string {
+	return t.In(location).Format("2006-01-02 15:04 MST")
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+)
+
+func handlerRename(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) != 1 {
+		return fmt.Errorf("rename requires exactly 1 argument (new username), found %v arguments", len(cmd.Arguments))
+	}
+	newName := cmd.Arguments[0]
+
+	_, err := s.db.GetUser(context.Background(), newName)
+	if err == nil {
+		return errors.New("the user already exists")
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check user existence: %w", err)
+	}
+
+	renameUserParams := database.RenameUserParams{
+		ID:        user.ID,
+		Name:      newName,
+		UpdatedAt: time.Now().UTC(),
+	}
+	_, err = s.db.RenameUser(context.Background(), renameUserParams)
+	if err != nil {
+		return fmt.Errorf("failed to rename user: %w", err)
+	}
+
+	fmt.Printf("User %s is now called %s\n", user.Name, newName)
+	return nil
+}
+
+func handlerDeleteAccount(s *state, cmd command, user database.User) error {
+	args, yes := popFlag(cmd.Arguments, "--yes")
+	if len(args) != 0 {
+		return fmt.Errorf("deleteaccount only accepts --yes, found %v arguments", args)
+	}
+
+	if user.HashedPassword.Valid {
+		password, err := readPassword("Password: ")
+		if err != nil {
+			return err
+		}
+		err = checkPassword(user, password)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !yes {
+		ok, err := confirm(fmt.Sprintf("This deletes user %s with all follows and read state. Continue?", user.Name))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Deletion cancelled.")
+			return nil
+		}
+	}
+
+	err := s.withTx(context.Background(), func(db database.Querier) error {
+		// The last user may go even if they are the admin, as whoever
+		// registers next becomes the admin.
+		users, err := db.GetUsers(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to get all users: %w", err)
+		}
+		if len(users) > 1 {
+			err = requireOtherAdmin(context.Background(), db, user)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Feeds other people follow must outlive their creator.
+		reassignFeedsOfUserParams := database.ReassignFeedsOfUserParams{
+			UserID:    user.ID,
+			UpdatedAt: time.Now().UTC(),
+		}
+		err = db.ReassignFeedsOfUser(context.Background(), reassignFeedsOfUserParams)
+		if err != nil {
+			return fmt.Errorf("failed to hand over feeds: %w", err)
+		}
+
+		err = db.DeleteUser(context.Background(), user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.config.SetSession("")
+	if err != nil {
+		return fmt.Errorf("failed to clear session: %w", err)
+	}
+
+	fmt.Printf("User %s has been deleted.\n", user.Name)
+	return nil
+}
+
+func handlerProfile(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) != 0 {
+		return fmt.Errorf("profile dosent require any arguments, found %v arguments", cmd.Arguments)
+	}
+
+	stats, err := s.db.GetUserStats(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user stats: %w", err)
+	}
+
+	role := "user"
+	if user.IsAdmin {
+		role = "admin"
+	}
+
+	fmt.Printf("Name:      %s\n", user.Name)
+	fmt.Printf("Role:      %s\n", role)
+	fmt.Printf("Member:    %s (since %s)\n", formatAge(time.Since(user.CreatedAt)), formatTime(user.CreatedAt, userLocation(user)))
+	fmt.Printf("Following: %d feeds\n", stats.FollowCount)
+	fmt.Printf("Unread:    %d posts\n", stats.UnreadCount)
+	return nil
+}
+
+func formatAge(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	switch {
+	case days == 0:
+		return "less than a day"
+	case days == 1:
+		return "1 day"
+	default:
+		return fmt.Sprintf("%d days", days)
+	}
+}
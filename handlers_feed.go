@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const systemOwnerName = "(system)"
+
+// feedOwnerName returns the name of the user owning feed, or
+// systemOwnerName for feeds whose creator has been deleted.
+func feedOwnerName(db database.Querier, feed database.Feed) (string, error) {
+	if !feed.UserID.Valid {
+		return systemOwnerName, nil
+	}
+	user, err := db.GetUserById(context.Background(), feed.UserID.UUID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user by id: %w", err)
+	}
+	return user.Name, nil
+}
+
+func handlerFeed(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) == 0 {
+		return errors.New("feed requires a subcommand (transfer or fullcontent)")
+	}
+
+	switch cmd.Arguments[0] {
+	case "transfer":
+		return feedTransfer(s, cmd.Arguments[1:])
+	case "fullcontent":
+		return feedFullContent(s, cmd.Arguments[1:])
+	default:
+		return fmt.Errorf("unknown feed subcommand %v, expected transfer or fullcontent", cmd.Arguments[0])
+	}
+}
+
+func feedTransfer(s *state, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("feed transfer requires exactly 2 arguments (feed URL, username), found %v arguments", len(args))
+	}
+	feedURL := args[0]
+	username := args[1]
+
+	var newOwner uuid.NullUUID
+	if username != systemOwnerName {
+		user, err := s.db.GetUser(context.Background(), username)
+		if err == sql.ErrNoRows {
+			return errors.New("the user dose not exists")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		newOwner = uuid.NullUUID{UUID: user.ID, Valid: true}
+	}
+
+	feed, err := s.db.GetFeedByURL(context.Background(), feedURL)
+	if err == sql.ErrNoRows {
+		return errors.New("the feed dose not exists")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find feed by url: %w", err)
+	}
+
+	transferFeedParams := database.TransferFeedParams{
+		ID:        feed.ID,
+		UserID:    newOwner,
+		UpdatedAt: time.Now().UTC(),
+	}
+	_, err = s.db.TransferFeed(context.Background(), transferFeedParams)
+	if err != nil {
+		return fmt.Errorf("failed to transfer feed: %w", err)
+	}
+
+	fmt.Printf("Feed '%s' now belongs to %s\n", feed.Url, username)
+	return nil
+}
+
+// handlerFolder moves a followed feed into a folder, or out of any folder
+// when no folder name is given. Folders only exist for the user who made
+// them.
+func handlerFolder(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) < 1 || len(cmd.Arguments) > 2 {
+		return fmt.Errorf("folder requires 1 or 2 arguments (feed URL and folder name), found %v arguments", len(cmd.Arguments))
+	}
+	feedURL := cmd.Arguments[0]
+	folder := ""
+	if len(cmd.Arguments) == 2 {
+		folder = cmd.Arguments[1]
+	}
+
+	n, err := setFolder(context.Background(), s.db, user, feedURL, folder)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("you are not following the feed %s", feedURL)
+	}
+
+	if folder == "" {
+		fmt.Printf("Removed %s from its folder\n", feedURL)
+		return nil
+	}
+	fmt.Printf("Moved %s to the folder %s\n", feedURL, folder)
+	return nil
+}
+
+func setFolder(ctx context.Context, db database.Querier, user database.User, feedURL, folder string) (int64, error) {
+	setFeedFollowFolderParams := database.SetFeedFollowFolderParams{
+		UserID:    user.ID,
+		Url:       feedURL,
+		Folder:    sql.NullString{String: folder, Valid: folder != ""},
+		UpdatedAt: time.Now().UTC(),
+	}
+	n, err := db.SetFeedFollowFolder(ctx, setFeedFollowFolderParams)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set folder: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/rssFeed"
+	"github.com/google/uuid"
+)
+
+const (
+	outputDefaultLimit = 50
+	outputDefaultLink  = "https://github.com/Romasav/gator"
+)
+
+// buildOutputFeed turns the posts matched by filter into a feed of their
+// own. Ids are derived from the user and the filter, so the same query
+// always yields the same feed and item ids.
+func buildOutputFeed(user database.User, filter postFilter, posts []database.GetPostsForUserRow, link, selfURL string) rssFeed.OutputFeed {
+	var what []string
+	if filter.UnreadOnly {
+		what = append(what, "unread")
+	}
+	if filter.StarredOnly {
+		what = append(what, "starred")
+	}
+	what = append(what, "posts")
+	if filter.FeedURL != "" {
+		what = append(what, "from", filter.FeedURL)
+	}
+	description := strings.Join(what, " ")
+
+	key := fmt.Sprintf("unread=%v starred=%v feed=%s", filter.UnreadOnly, filter.StarredOnly, filter.FeedURL)
+	feed := rssFeed.OutputFeed{
+		ID:          "urn:uuid:" + uuid.NewSHA1(user.ID, []byte(key)).String(),
+		Title:       fmt.Sprintf("%s's %s", user.Name, description),
+		Link:        link,
+		SelfURL:     selfURL,
+		Description: fmt.Sprintf("Generated by gator for %s: %s", user.Name, description),
+		Author:      user.Name,
+	}
+
+	for _, post := range posts {
+		published := post.CreatedAt
+		if post.PublishedAt.Valid {
+			published = post.PublishedAt.Time
+		}
+		if published.After(feed.Updated) {
+			feed.Updated = published
+		}
+
+		feed.Items = append(feed.Items, rssFeed.OutputItem{
+			GUID:        "urn:uuid:" + post.ID.String(),
+			Title:       post.Title,
+			Link:        post.Url,
+			Description: post.Description.String,
+			Published:   published,
+			SourceTitle: post.FeedName,
+			SourceURL:   post.FeedUrl,
+		})
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Now().UTC()
+	}
+	return feed
+}
+
+func renderOutputFeed(feed rssFeed.OutputFeed, atom bool) ([]byte, error) {
+	if atom {
+		return feed.Atom()
+	}
+	return feed.RSS()
+}
+
+func handlerFeedOut(s *state, cmd command, user database.User) error {
+	filter := postFilter{Limit: outputDefaultLimit}
+
+	args, feedURL, err := popFlagValue(cmd.Arguments, "--feed")
+	if err != nil {
+		return err
+	}
+	filter.FeedURL = feedURL
+	args, link, err := popFlagValue(args, "--link")
+	if err != nil {
+		return err
+	}
+	if link == "" {
+		link = outputDefaultLink
+	}
+	args, outputPath, err := popFlagValue(args, "--output")
+	if err != nil {
+		return err
+	}
+	args, filter.UnreadOnly = popFlag(args, "--unread")
+	args, filter.StarredOnly = popFlag(args, "--starred")
+	args, atom := popFlag(args, "--atom")
+
+	if len(args) > 1 {
+		return fmt.Errorf("feedout takes at most 1 argument (limit), found %v arguments", len(args))
+	}
+	if len(args) > 0 {
+		filter.Limit, err = strconv.Atoi(args[0])
+		if err != nil || filter.Limit < 1 {
+			return fmt.Errorf("invalid limit: %s", args[0])
+		}
+	}
+
+	posts, err := s.db.GetPostsForUser(context.Background(), filter.params(user.ID))
+	if err != nil {
+		return fmt.Errorf("failed to fetch posts: %w", err)
+	}
+
+	data, err := renderOutputFeed(buildOutputFeed(user, filter, posts, link, ""), atom)
+	if err != nil {
+		return fmt.Errorf("failed to render feed: %w", err)
+	}
+
+	if outputPath == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	err = os.WriteFile(outputPath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write feed: %w", err)
+	}
+	fmt.Printf("Wrote %v posts to %s\n", len(posts), outputPath)
+	return nil
+}
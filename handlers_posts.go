@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/rssFeed"
+	"github.com/google/uuid"
+)
+
+func setPostRead(ctx context.Context, db database.Querier, userID, postID uuid.UUID, read bool) error {
+	markPostReadParams := database.MarkPostReadParams{
+		UserID: userID,
+		PostID: postID,
+		ReadAt: sql.NullTime{Time: time.Now().UTC(), Valid: read},
+	}
+	err := db.MarkPostRead(ctx, markPostReadParams)
+	if err != nil {
+		return fmt.Errorf("failed to update read state: %w", err)
+	}
+	return nil
+}
+
+func setPostStarred(ctx context.Context, db database.Querier, userID, postID uuid.UUID, starred bool) error {
+	markPostStarredParams := database.MarkPostStarredParams{
+		UserID:    userID,
+		PostID:    postID,
+		StarredAt: sql.NullTime{Time: time.Now().UTC(), Valid: starred},
+	}
+	err := db.MarkPostStarred(ctx, markPostStarredParams)
+	if err != nil {
+		return fmt.Errorf("failed to update starred state: %w", err)
+	}
+	return nil
+}
+
+// getFollowedPost returns the post with postID if the user follows its
+// feed. Posts of other feeds are not theirs to see or mark, and come back
+// as sql.ErrNoRows like missing ones so their ids are not revealed.
+func getFollowedPost(ctx context.Context, db database.Querier, userID, postID uuid.UUID) (database.Post, error) {
+	post, err := db.GetPostById(ctx, postID)
+	if err == sql.ErrNoRows {
+		return database.Post{}, err
+	}
+	if err != nil {
+		return database.Post{}, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	getFeedFollowParams := database.GetFeedFollowParams{
+		UserID: userID,
+		FeedID: post.FeedID,
+	}
+	_, err = db.GetFeedFollow(ctx, getFeedFollowParams)
+	if err == sql.ErrNoRows {
+		return database.Post{}, err
+	}
+	if err != nil {
+		return database.Post{}, fmt.Errorf("failed to get feed follow: %w", err)
+	}
+	return post, nil
+}
+
+// forEachPostURL looks up every URL in args as a post and calls fn with it.
+func forEachPostURL(s *state, name string, args []string, fn func(post database.Post) error) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%v requires at least 1 argument (post URL)", name)
+	}
+
+	for _, postURL := range args {
+		post, err := s.db.GetPostByURL(context.Background(), postURL)
+		if err == sql.ErrNoRows && rssFeed.NormalizeURL(postURL) != postURL {
+			// Posts are stored under their normalized URL, so a link copied
+			// from elsewhere may still carry tracking parameters.
+			post, err = s.db.GetPostByURL(context.Background(), rssFeed.NormalizeURL(postURL))
+		}
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("the post %s dose not exists", postURL)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find post by url: %w", err)
+		}
+
+		err = fn(post)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handlerMarkRead(s *state, cmd command, user database.User) error {
+	return forEachPostURL(s, cmd.Name, cmd.Arguments, func(post database.Post) error {
+		err := setPostRead(context.Background(), s.db, user.ID, post.ID, true)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Marked '%s' as read\n", post.Title)
+		return nil
+	})
+}
+
+func handlerMarkUnread(s *state, cmd command, user database.User) error {
+	return forEachPostURL(s, cmd.Name, cmd.Arguments, func(post database.Post) error {
+		err := setPostRead(context.Background(), s.db, user.ID, post.ID, false)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Marked '%s' as unread\n", post.Title)
+		return nil
+	})
+}
+
+func handlerStar(s *state, cmd command, user database.User) error {
+	return forEachPostURL(s, cmd.Name, cmd.Arguments, func(post database.Post) error {
+		err := setPostStarred(context.Background(), s.db, user.ID, post.ID, true)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Starred '%s'\n", post.Title)
+		return nil
+	})
+}
+
+func handlerUnstar(s *state, cmd command, user database.User) error {
+	return forEachPostURL(s, cmd.Name, cmd.Arguments, func(post database.Post) error {
+		err := setPostStarred(context.Background(), s.db, user.ID, post.ID, false)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Unstarred '%s'\n", post.Title)
+		return nil
+	})
+}
+
+func handlerToken(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) == 0 {
+		return errors.New("token requires a subcommand (create, list or revoke)")
+	}
+	args := cmd.Arguments[1:]
+
+	switch cmd.Arguments[0] {
+	case "create":
+		if len(args) != 1 {
+			return fmt.Errorf("token create requires exactly 1 argument (name), found %v arguments", len(args))
+		}
+		token, err := newToken()
+		if err != nil {
+			return err
+		}
+		createAPITokenParams := database.CreateAPITokenParams{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			Name:      args[0],
+			TokenHash: hashToken(token),
+			CreatedAt: time.Now().UTC(),
+		}
+		apiToken, err := s.db.CreateAPIToken(context.Background(), createAPITokenParams)
+		if err != nil {
+			return fmt.Errorf("failed to create api token: %w", err)
+		}
+		fmt.Printf("Created API token %s (%s). It will not be shown again:\n%s\n", apiToken.Name, apiToken.ID, token)
+	case "list":
+		if len(args) != 0 {
+			return fmt.Errorf("token list dosent require any arguments, found %v arguments", args)
+		}
+		apiTokens, err := s.db.GetAPITokensForUser(context.Background(), user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get api tokens: %w", err)
+		}
+		location := userLocation(user)
+		for _, apiToken := range apiTokens {
+			lastUsed := "never"
+			if apiToken.LastUsedAt.Valid {
+				lastUsed = formatTime(apiToken.LastUsedAt.Time, location)
+			}
+			fmt.Printf("%s  %-20s created %s, last used %s\n", apiToken.ID, apiToken.Name, formatTime(apiToken.CreatedAt, location), lastUsed)
+		}
+	case "revoke":
+		if len(args) != 1 {
+			return fmt.Errorf("token revoke requires exactly 1 argument (token id), found %v arguments", len(args))
+		}
+		id, err := uuid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid token id: %s", args[0])
+		}
+		deleteAPITokenParams := database.DeleteAPITokenParams{
+			ID:     id,
+			UserID: user.ID,
+		}
+		deleted, err := s.db.DeleteAPIToken(context.Background(), deleteAPITokenParams)
+		if err != nil {
+			return fmt.Errorf("failed to revoke api token: %w", err)
+		}
+		if deleted == 0 {
+			return errors.New("the api token dose not exists")
+		}
+		fmt.Println("The API token has been revoked.")
+	default:
+		return fmt.Errorf("unknown token subcommand %v, expected create, list or revoke", cmd.Arguments[0])
+	}
+
+	return nil
+}
@@ -8,12 +8,27 @@ import (
 )
 
 type Config struct {
-	Username string `json:"current_user_name"`
-	DbUrl    string `json:"db_url"`
+	SessionToken string `json:"session_token,omitempty"`
+	DbUrl        string `json:"db_url"`
+	SMTP         *SMTP  `json:"smtp,omitempty"`
+	// DownloadDir is where podcast episodes are saved, ~/Podcasts if empty.
+	DownloadDir string `json:"download_dir,omitempty"`
 }
 
-func (con *Config) SetUpUser(username string) error {
-	con.Username = username
+// SMTP is the mail server digests are sent through. Username and Password
+// may be left empty for servers that do not require authentication.
+type SMTP struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	From     string `json:"from"`
+}
+
+// SetSession stores the token of the logged-in session. An empty token logs
+// the user out.
+func (con *Config) SetSession(token string) error {
+	con.SessionToken = token
 	err := write(*con)
 	if err != nil {
 		return fmt.Errorf("could not write to config: %w", err)
@@ -51,7 +66,7 @@ func write(config Config) error {
 		return fmt.Errorf("could not find config file: %w", err)
 	}
 
-	err = os.WriteFile(configFilePath, jsonData, 0644)
+	err = os.WriteFile(configFilePath, jsonData, 0600)
 	if err != nil {
 		return fmt.Errorf("could not write to config file: %w", err)
 	}
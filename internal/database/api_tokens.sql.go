@@ -0,0 +1,158 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: api_tokens.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createAPIToken = `-- name: CreateAPIToken :one
+INSERT INTO api_tokens (id, user_id, name, token_hash, created_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, name, token_hash, created_at, last_used_at
+`
+
+type CreateAPITokenParams struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	TokenHash string
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, createAPIToken,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.TokenHash,
+		arg.CreatedAt,
+	)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const deleteAPIToken = `-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteAPITokenParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteAPIToken(ctx context.Context, arg DeleteAPITokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAPIToken, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteAPITokensByName = `-- name: DeleteAPITokensByName :exec
+DELETE FROM api_tokens
+WHERE user_id = $1 AND name = $2
+`
+
+type DeleteAPITokensByNameParams struct {
+	UserID uuid.UUID
+	Name   string
+}
+
+func (q *Queries) DeleteAPITokensByName(ctx context.Context, arg DeleteAPITokensByNameParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAPITokensByName, arg.UserID, arg.Name)
+	return err
+}
+
+const getAPITokensForUser = `-- name: GetAPITokensForUser :many
+SELECT id, user_id, name, token_hash, created_at, last_used_at FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetAPITokensForUser(ctx context.Context, userID uuid.UUID) ([]ApiToken, error) {
+	rows, err := q.db.QueryContext(ctx, getAPITokensForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiToken
+	for rows.Next() {
+		var i ApiToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.TokenHash,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserByAPIToken = `-- name: GetUserByAPIToken :one
+SELECT users.id, users.created_at, users.updated_at, users.name, users.timezone, users.hashed_password, users.is_admin, users.email, users.last_digest_at, users.last_digest_post_id
+FROM api_tokens
+JOIN users ON users.id = api_tokens.user_id
+WHERE api_tokens.token_hash = $1
+`
+
+func (q *Queries) GetUserByAPIToken(ctx context.Context, tokenHash string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByAPIToken, tokenHash)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Timezone,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.LastDigestPostID,
+	)
+	return i, err
+}
+
+const touchAPIToken = `-- name: TouchAPIToken :exec
+UPDATE api_tokens
+SET last_used_at = $2
+WHERE token_hash = $1
+`
+
+type TouchAPITokenParams struct {
+	TokenHash  string
+	LastUsedAt sql.NullTime
+}
+
+func (q *Queries) TouchAPIToken(ctx context.Context, arg TouchAPITokenParams) error {
+	_, err := q.db.ExecContext(ctx, touchAPIToken, arg.TokenHash, arg.LastUsedAt)
+	return err
+}
@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,7 +16,7 @@ import (
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_content, last_parse_warning
 `
 
 type CreateFeedParams struct {
@@ -24,7 +25,7 @@ type CreateFeedParams struct {
 	UpdatedAt time.Time
 	Name      string
 	Url       string
-	UserID    uuid.UUID
+	UserID    uuid.NullUUID
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -45,12 +46,32 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.FetchFullContent,
+		&i.LastParseWarning,
 	)
 	return i, err
 }
 
+const deleteAllFeeds = `-- name: DeleteAllFeeds :exec
+DELETE FROM feeds
+`
+
+func (q *Queries) DeleteAllFeeds(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllFeeds)
+	return err
+}
+
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE url = $1 LIMIT 1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_content, last_parse_warning FROM feeds WHERE url = $1 LIMIT 1
 `
 
 func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
@@ -64,12 +85,14 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.FetchFullContent,
+		&i.LastParseWarning,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_content, last_parse_warning FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -89,6 +112,8 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.FetchFullContent,
+			&i.LastParseWarning,
 		); err != nil {
 			return nil, err
 		}
@@ -104,7 +129,7 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 }
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_content, last_parse_warning FROM feeds
 WHERE last_fetched_at IS NULL
    OR last_fetched_at = (
        SELECT MIN(last_fetched_at) 
@@ -125,17 +150,131 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.FetchFullContent,
+		&i.LastParseWarning,
 	)
 	return i, err
 }
 
 const markFeedFetched = `-- name: MarkFeedFetched :exec
 UPDATE feeds
-SET last_fetched_at = now(), updated_at = now()
+SET last_fetched_at = $1::timestamptz, updated_at = $1::timestamptz
+WHERE id = $2
+`
+
+type MarkFeedFetchedParams struct {
+	FetchedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.FetchedAt, arg.ID)
+	return err
+}
+
+const reassignFeedsOfUser = `-- name: ReassignFeedsOfUser :exec
+UPDATE feeds
+SET user_id = (
+        SELECT feed_follows.user_id FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id
+        AND feed_follows.user_id <> $1
+        ORDER BY feed_follows.created_at
+        LIMIT 1
+    ),
+    updated_at = $2
+WHERE feeds.user_id = $1
+AND EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id
+    AND feed_follows.user_id <> $1
+)
+`
+
+type ReassignFeedsOfUserParams struct {
+	UserID    uuid.UUID
+	UpdatedAt time.Time
+}
+
+// Hands every feed the user added to the longest-standing other follower.
+// Feeds nobody else follows keep their owner until the user is deleted,
+// at which point they fall back to the system (NULL owner).
+func (q *Queries) ReassignFeedsOfUser(ctx context.Context, arg ReassignFeedsOfUserParams) error {
+	_, err := q.db.ExecContext(ctx, reassignFeedsOfUser, arg.UserID, arg.UpdatedAt)
+	return err
+}
+
+const setFeedFetchFullContent = `-- name: SetFeedFetchFullContent :one
+UPDATE feeds
+SET fetch_full_content = $2, updated_at = $3
 WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_content, last_parse_warning
 `
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
+type SetFeedFetchFullContentParams struct {
+	ID               uuid.UUID
+	FetchFullContent bool
+	UpdatedAt        time.Time
+}
+
+func (q *Queries) SetFeedFetchFullContent(ctx context.Context, arg SetFeedFetchFullContentParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeedFetchFullContent, arg.ID, arg.FetchFullContent, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.FetchFullContent,
+		&i.LastParseWarning,
+	)
+	return i, err
+}
+
+const setFeedParseWarning = `-- name: SetFeedParseWarning :exec
+UPDATE feeds
+SET last_parse_warning = $2
+WHERE id = $1
+`
+
+type SetFeedParseWarningParams struct {
+	ID               uuid.UUID
+	LastParseWarning sql.NullString
+}
+
+func (q *Queries) SetFeedParseWarning(ctx context.Context, arg SetFeedParseWarningParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedParseWarning, arg.ID, arg.LastParseWarning)
 	return err
 }
+
+const transferFeed = `-- name: TransferFeed :one
+UPDATE feeds
+SET user_id = $2, updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, fetch_full_content, last_parse_warning
+`
+
+type TransferFeedParams struct {
+	ID        uuid.UUID
+	UserID    uuid.NullUUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) TransferFeed(ctx context.Context, arg TransferFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, transferFeed, arg.ID, arg.UserID, arg.UpdatedAt)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.FetchFullContent,
+		&i.LastParseWarning,
+	)
+	return i, err
+}
@@ -11,14 +11,38 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApiToken struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	TokenHash  string
+	CreatedAt  time.Time
+	LastUsedAt sql.NullTime
+}
+
+type Episode struct {
+	PostID          uuid.UUID
+	MediaUrl        string
+	MediaType       string
+	MediaLength     sql.NullInt64
+	DurationSeconds sql.NullInt32
+	EpisodeNumber   sql.NullInt32
+	SeasonNumber    sql.NullInt32
+	ImageUrl        sql.NullString
+	Explicit        sql.NullBool
+	CreatedAt       time.Time
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Name             string
+	Url              string
+	UserID           uuid.NullUUID
+	LastFetchedAt    sql.NullTime
+	FetchFullContent bool
+	LastParseWarning sql.NullString
 }
 
 type FeedFollow struct {
@@ -27,22 +51,106 @@ type FeedFollow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	Folder    sql.NullString
+}
+
+type FilterRule struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Field     string
+	Pattern   string
+	IsRegex   bool
+	Action    string
+	Tag       sql.NullString
+	CreatedAt time.Time
 }
 
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Title            string
+	Url              string
+	Description      sql.NullString
+	PublishedAt      sql.NullTime
+	FeedID           uuid.UUID
+	ArticleHtml      sql.NullString
+	ArticleText      sql.NullString
+	ArticleFetchedAt sql.NullTime
+	Content          sql.NullString
+	Author           sql.NullString
+	Categories       sql.NullString
 }
 
-type User struct {
+type PostEnclosure struct {
 	ID        uuid.UUID
+	PostID    uuid.UUID
+	Url       string
+	MediaType string
+	Length    sql.NullInt64
 	CreatedAt time.Time
-	UpdatedAt time.Time
+}
+
+type PostState struct {
+	UserID       uuid.UUID
+	PostID       uuid.UUID
+	ReadAt       sql.NullTime
+	StarredAt    sql.NullTime
+	MutedAt      sql.NullTime
+	PlayedAt     sql.NullTime
+	DownloadedAt sql.NullTime
+	DownloadPath sql.NullString
+}
+
+type PostTag struct {
+	PostID    uuid.UUID
+	TagID     uuid.UUID
+	CreatedAt time.Time
+}
+
+type Session struct {
+	TokenHash string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type Tag struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
 	Name      string
+	CreatedAt time.Time
+}
+
+type User struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Name             string
+	Timezone         string
+	HashedPassword   sql.NullString
+	IsAdmin          bool
+	Email            sql.NullString
+	LastDigestAt     sql.NullTime
+	LastDigestPostID uuid.NullUUID
+}
+
+type Webhook struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Url       string
+	Secret    string
+	FeedID    uuid.NullUUID
+	Keyword   sql.NullString
+	CreatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID         uuid.UUID
+	WebhookID  uuid.UUID
+	PostID     uuid.NullUUID
+	Attempt    int32
+	StatusCode sql.NullInt32
+	Error      sql.NullString
+	CreatedAt  time.Time
 }
@@ -14,10 +14,10 @@ import (
 )
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content, author, categories)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 ON CONFLICT (url) DO NOTHING
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, article_html, article_text, article_fetched_at, content, author, categories
 `
 
 type CreatePostParams struct {
@@ -29,6 +29,9 @@ type CreatePostParams struct {
 	Description sql.NullString
 	PublishedAt sql.NullTime
 	FeedID      uuid.UUID
+	Content     sql.NullString
+	Author      sql.NullString
+	Categories  sql.NullString
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -41,6 +44,9 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Content,
+		arg.Author,
+		arg.Categories,
 	)
 	var i Post
 	err := row.Scan(
@@ -52,33 +58,475 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.ArticleHtml,
+		&i.ArticleText,
+		&i.ArticleFetchedAt,
+		&i.Content,
+		&i.Author,
+		&i.Categories,
 	)
 	return i, err
 }
 
+const createPostEnclosure = `-- name: CreatePostEnclosure :exec
+INSERT INTO post_enclosures (id, post_id, url, media_type, length, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (post_id, url) DO NOTHING
+`
+
+type CreatePostEnclosureParams struct {
+	ID        uuid.UUID
+	PostID    uuid.UUID
+	Url       string
+	MediaType string
+	Length    sql.NullInt64
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreatePostEnclosure(ctx context.Context, arg CreatePostEnclosureParams) error {
+	_, err := q.db.ExecContext(ctx, createPostEnclosure,
+		arg.ID,
+		arg.PostID,
+		arg.Url,
+		arg.MediaType,
+		arg.Length,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const getFollowedPosts = `-- name: GetFollowedPosts :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.article_html, posts.article_text, posts.article_fetched_at, posts.content, posts.author, posts.categories,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY posts.created_at DESC
+`
+
+type GetFollowedPostsRow struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Title            string
+	Url              string
+	Description      sql.NullString
+	PublishedAt      sql.NullTime
+	FeedID           uuid.UUID
+	ArticleHtml      sql.NullString
+	ArticleText      sql.NullString
+	ArticleFetchedAt sql.NullTime
+	Content          sql.NullString
+	Author           sql.NullString
+	Categories       sql.NullString
+	FeedName         string
+	FeedUrl          string
+}
+
+// Every stored post from the feeds a user follows, for applying filter rules
+// retroactively.
+func (q *Queries) GetFollowedPosts(ctx context.Context, userID uuid.UUID) ([]GetFollowedPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowedPosts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFollowedPostsRow
+	for rows.Next() {
+		var i GetFollowedPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.ArticleHtml,
+			&i.ArticleText,
+			&i.ArticleFetchedAt,
+			&i.Content,
+			&i.Author,
+			&i.Categories,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostById = `-- name: GetPostById :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, article_html, article_text, article_fetched_at, content, author, categories FROM posts WHERE id = $1
+`
+
+func (q *Queries) GetPostById(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostById, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.ArticleHtml,
+		&i.ArticleText,
+		&i.ArticleFetchedAt,
+		&i.Content,
+		&i.Author,
+		&i.Categories,
+	)
+	return i, err
+}
+
+const getPostByURL = `-- name: GetPostByURL :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, article_html, article_text, article_fetched_at, content, author, categories FROM posts WHERE url = $1
+`
+
+func (q *Queries) GetPostByURL(ctx context.Context, url string) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByURL, url)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.ArticleHtml,
+		&i.ArticleText,
+		&i.ArticleFetchedAt,
+		&i.Content,
+		&i.Author,
+		&i.Categories,
+	)
+	return i, err
+}
+
+const getPostEnclosures = `-- name: GetPostEnclosures :many
+SELECT id, post_id, url, media_type, length, created_at FROM post_enclosures
+WHERE post_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetPostEnclosures(ctx context.Context, postID uuid.UUID) ([]PostEnclosure, error) {
+	rows, err := q.db.QueryContext(ctx, getPostEnclosures, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostEnclosure
+	for rows.Next() {
+		var i PostEnclosure
+		if err := rows.Scan(
+			&i.ID,
+			&i.PostID,
+			&i.Url,
+			&i.MediaType,
+			&i.Length,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostForUserByIDRange = `-- name: GetPostForUserByIDRange :one
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.article_html, posts.article_text, posts.article_fetched_at, posts.content, posts.author, posts.categories,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    post_states.read_at,
+    post_states.starred_at,
+    COALESCE((
+        SELECT string_agg(tags.name, ',' ORDER BY tags.name)
+        FROM post_tags
+        JOIN tags ON tags.id = post_tags.tag_id
+        WHERE post_tags.post_id = posts.id AND tags.user_id = $1
+    ), '')::text AS tags
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = $1
+WHERE posts.id BETWEEN $2::uuid AND $3::uuid
+ORDER BY posts.id
+LIMIT 1
+`
+
+type GetPostForUserByIDRangeParams struct {
+	UserID uuid.UUID
+	MinID  uuid.UUID
+	MaxID  uuid.UUID
+}
+
+type GetPostForUserByIDRangeRow struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Title            string
+	Url              string
+	Description      sql.NullString
+	PublishedAt      sql.NullTime
+	FeedID           uuid.UUID
+	ArticleHtml      sql.NullString
+	ArticleText      sql.NullString
+	ArticleFetchedAt sql.NullTime
+	Content          sql.NullString
+	Author           sql.NullString
+	Categories       sql.NullString
+	FeedName         string
+	FeedUrl          string
+	ReadAt           sql.NullTime
+	StarredAt        sql.NullTime
+	Tags             string
+}
+
+// Looks up a post of a followed feed whose id lies between min_id and
+// max_id. The Google Reader API refers to items by the first 8 bytes of
+// their id, which gives such a range.
+func (q *Queries) GetPostForUserByIDRange(ctx context.Context, arg GetPostForUserByIDRangeParams) (GetPostForUserByIDRangeRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostForUserByIDRange, arg.UserID, arg.MinID, arg.MaxID)
+	var i GetPostForUserByIDRangeRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.ArticleHtml,
+		&i.ArticleText,
+		&i.ArticleFetchedAt,
+		&i.Content,
+		&i.Author,
+		&i.Categories,
+		&i.FeedName,
+		&i.FeedUrl,
+		&i.ReadAt,
+		&i.StarredAt,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getPostsForDigest = `-- name: GetPostsForDigest :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.article_html, posts.article_text, posts.article_fetched_at, posts.content, posts.author, posts.categories,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+AND (posts.created_at > $2 OR (posts.created_at = $2 AND posts.id > $3::uuid))
+AND posts.created_at <= $4
+AND post_states.read_at IS NULL
+AND post_states.muted_at IS NULL
+ORDER BY posts.created_at, posts.id
+LIMIT $5
+`
+
+type GetPostsForDigestParams struct {
+	UserID  uuid.UUID
+	Since   time.Time
+	SinceID uuid.NullUUID
+	Until   time.Time
+	Limit   int32
+}
+
+type GetPostsForDigestRow struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Title            string
+	Url              string
+	Description      sql.NullString
+	PublishedAt      sql.NullTime
+	FeedID           uuid.UUID
+	ArticleHtml      sql.NullString
+	ArticleText      sql.NullString
+	ArticleFetchedAt sql.NullTime
+	Content          sql.NullString
+	Author           sql.NullString
+	Categories       sql.NullString
+	FeedName         string
+	FeedUrl          string
+}
+
+// Unread posts from the feeds a user follows that arrived in (since, until],
+// oldest first, so a digest cut short by the limit can continue where it
+// stopped. Posts that arrived at since itself are included when their id
+// comes after since_id, the last post of the digest before.
+func (q *Queries) GetPostsForDigest(ctx context.Context, arg GetPostsForDigestParams) ([]GetPostsForDigestRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForDigest,
+		arg.UserID,
+		arg.Since,
+		arg.SinceID,
+		arg.Until,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForDigestRow
+	for rows.Next() {
+		var i GetPostsForDigestRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.ArticleHtml,
+			&i.ArticleText,
+			&i.ArticleFetchedAt,
+			&i.Content,
+			&i.Author,
+			&i.Categories,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.article_html, posts.article_text, posts.article_fetched_at, posts.content, posts.author, posts.categories,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    post_states.read_at,
+    post_states.starred_at,
+    COALESCE((
+        SELECT string_agg(tags.name, ',' ORDER BY tags.name)
+        FROM post_tags
+        JOIN tags ON tags.id = post_tags.tag_id
+        WHERE post_tags.post_id = posts.id AND tags.user_id = feed_follows.user_id
+    ), '')::text AS tags
 FROM posts
 JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
 WHERE feed_follows.user_id = $1
+AND (NOT $2::boolean OR post_states.read_at IS NULL)
+AND (NOT $3::boolean OR post_states.starred_at IS NOT NULL)
+AND ($4::text IS NULL OR feeds.url = $4::text)
+AND ($5::text IS NULL OR feed_follows.folder = $5::text)
+AND (post_states.muted_at IS NOT NULL) = $6::boolean
+AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_tags
+    JOIN tags ON tags.id = post_tags.tag_id
+    WHERE post_tags.post_id = posts.id
+    AND tags.user_id = feed_follows.user_id
+    AND tags.name = $7::text
+))
+AND ($8::text IS NULL
+    OR posts.title ILIKE '%' || $8::text || '%' ESCAPE '\'
+    OR posts.description ILIKE '%' || $8::text || '%' ESCAPE '\'
+    OR posts.content ILIKE '%' || $8::text || '%' ESCAPE '\'
+    OR posts.article_text ILIKE '%' || $8::text || '%' ESCAPE '\')
 ORDER BY posts.published_at DESC
-LIMIT $2
+LIMIT $10
+OFFSET $9
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID      uuid.UUID
+	UnreadOnly  bool
+	StarredOnly bool
+	FeedUrl     sql.NullString
+	Folder      sql.NullString
+	MutedOnly   bool
+	Tag         sql.NullString
+	Query       sql.NullString
+	Offset      int32
+	Limit       int32
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+type GetPostsForUserRow struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Title            string
+	Url              string
+	Description      sql.NullString
+	PublishedAt      sql.NullTime
+	FeedID           uuid.UUID
+	ArticleHtml      sql.NullString
+	ArticleText      sql.NullString
+	ArticleFetchedAt sql.NullTime
+	Content          sql.NullString
+	Author           sql.NullString
+	Categories       sql.NullString
+	FeedName         string
+	FeedUrl          string
+	ReadAt           sql.NullTime
+	StarredAt        sql.NullTime
+	Tags             string
+}
+
+// query is a LIKE pattern, so callers escape %, _ and \ in what the user typed.
+func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.StarredOnly,
+		arg.FeedUrl,
+		arg.Folder,
+		arg.MutedOnly,
+		arg.Tag,
+		arg.Query,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []GetPostsForUserRow
 	for rows.Next() {
-		var i Post
+		var i GetPostsForUserRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -88,6 +536,17 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.ArticleHtml,
+			&i.ArticleText,
+			&i.ArticleFetchedAt,
+			&i.Content,
+			&i.Author,
+			&i.Categories,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.ReadAt,
+			&i.StarredAt,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -101,3 +560,80 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const getUnreadCountsForUser = `-- name: GetUnreadCountsForUser :many
+SELECT
+    feeds.id AS feed_id,
+    feeds.url AS feed_url,
+    feed_follows.folder,
+    COUNT(posts.id) AS unread_count,
+    MAX(posts.created_at)::timestamptz AS newest_created_at
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+JOIN posts ON posts.feed_id = feeds.id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+AND post_states.read_at IS NULL
+AND post_states.muted_at IS NULL
+GROUP BY feeds.id, feeds.url, feed_follows.folder
+`
+
+type GetUnreadCountsForUserRow struct {
+	FeedID          uuid.UUID
+	FeedUrl         string
+	Folder          sql.NullString
+	UnreadCount     int64
+	NewestCreatedAt time.Time
+}
+
+func (q *Queries) GetUnreadCountsForUser(ctx context.Context, userID uuid.UUID) ([]GetUnreadCountsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadCountsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUnreadCountsForUserRow
+	for rows.Next() {
+		var i GetUnreadCountsForUserRow
+		if err := rows.Scan(
+			&i.FeedID,
+			&i.FeedUrl,
+			&i.Folder,
+			&i.UnreadCount,
+			&i.NewestCreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPostArticle = `-- name: SetPostArticle :exec
+UPDATE posts
+SET article_html = $2, article_text = $3, article_fetched_at = $4
+WHERE id = $1
+`
+
+type SetPostArticleParams struct {
+	ID               uuid.UUID
+	ArticleHtml      sql.NullString
+	ArticleText      sql.NullString
+	ArticleFetchedAt sql.NullTime
+}
+
+func (q *Queries) SetPostArticle(ctx context.Context, arg SetPostArticleParams) error {
+	_, err := q.db.ExecContext(ctx, setPostArticle,
+		arg.ID,
+		arg.ArticleHtml,
+		arg.ArticleText,
+		arg.ArticleFetchedAt,
+	)
+	return err
+}
@@ -7,35 +7,52 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const countOtherAdmins = `-- name: CountOtherAdmins :one
+SELECT COUNT(*) FROM users WHERE is_admin AND id <> $1
+`
+
+func (q *Queries) CountOtherAdmins(ctx context.Context, id uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOtherAdmins, id)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, hashed_password, is_admin)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5,
+    NOT EXISTS (SELECT 1 FROM users)
 )
-RETURNING id, created_at, updated_at, name
+RETURNING id, created_at, updated_at, name, timezone, hashed_password, is_admin, email, last_digest_at, last_digest_post_id
 `
 
 type CreateUserParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Name           string
+	HashedPassword sql.NullString
 }
 
+// The first user to register becomes the admin.
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
 	row := q.db.QueryRowContext(ctx, createUser,
 		arg.ID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.Name,
+		arg.HashedPassword,
 	)
 	var i User
 	err := row.Scan(
@@ -43,6 +60,12 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.Timezone,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.LastDigestPostID,
 	)
 	return i, err
 }
@@ -56,8 +79,17 @@ func (q *Queries) DeleteAllUsers(ctx context.Context) error {
 	return err
 }
 
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
 const getUser = `-- name: GetUser :one
-SELECT id, created_at, updated_at, name FROM users WHERE name = $1
+SELECT id, created_at, updated_at, name, timezone, hashed_password, is_admin, email, last_digest_at, last_digest_post_id FROM users WHERE name = $1
 `
 
 func (q *Queries) GetUser(ctx context.Context, name string) (User, error) {
@@ -68,12 +100,18 @@ func (q *Queries) GetUser(ctx context.Context, name string) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.Timezone,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.LastDigestPostID,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, created_at, updated_at, name FROM users WHERE id = $1
+SELECT id, created_at, updated_at, name, timezone, hashed_password, is_admin, email, last_digest_at, last_digest_post_id FROM users WHERE id = $1
 `
 
 func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
@@ -84,12 +122,42 @@ func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.Timezone,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.LastDigestPostID,
 	)
 	return i, err
 }
 
+const getUserStats = `-- name: GetUserStats :one
+SELECT
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = $1) AS follow_count,
+    (SELECT COUNT(*)
+     FROM posts
+     JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+     LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+     WHERE feed_follows.user_id = $1
+     AND post_states.read_at IS NULL
+     AND post_states.muted_at IS NULL) AS unread_count
+`
+
+type GetUserStatsRow struct {
+	FollowCount int64
+	UnreadCount int64
+}
+
+func (q *Queries) GetUserStats(ctx context.Context, userID uuid.UUID) (GetUserStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserStats, userID)
+	var i GetUserStatsRow
+	err := row.Scan(&i.FollowCount, &i.UnreadCount)
+	return i, err
+}
+
 const getUsers = `-- name: GetUsers :many
-SELECT id, created_at, updated_at, name FROM users
+SELECT id, created_at, updated_at, name, timezone, hashed_password, is_admin, email, last_digest_at, last_digest_post_id FROM users
 `
 
 func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
@@ -106,6 +174,12 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Name,
+			&i.Timezone,
+			&i.HashedPassword,
+			&i.IsAdmin,
+			&i.Email,
+			&i.LastDigestAt,
+			&i.LastDigestPostID,
 		); err != nil {
 			return nil, err
 		}
@@ -119,3 +193,215 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 	}
 	return items, nil
 }
+
+const getUsersWithEmail = `-- name: GetUsersWithEmail :many
+SELECT id, created_at, updated_at, name, timezone, hashed_password, is_admin, email, last_digest_at, last_digest_post_id FROM users
+WHERE email IS NOT NULL
+ORDER BY name
+`
+
+func (q *Queries) GetUsersWithEmail(ctx context.Context) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersWithEmail)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Timezone,
+			&i.HashedPassword,
+			&i.IsAdmin,
+			&i.Email,
+			&i.LastDigestAt,
+			&i.LastDigestPostID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const renameUser = `-- name: RenameUser :one
+UPDATE users
+SET name = $2, updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, timezone, hashed_password, is_admin, email, last_digest_at, last_digest_post_id
+`
+
+type RenameUserParams struct {
+	ID        uuid.UUID
+	Name      string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) RenameUser(ctx context.Context, arg RenameUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, renameUser, arg.ID, arg.Name, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Timezone,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.LastDigestPostID,
+	)
+	return i, err
+}
+
+const setUserAdmin = `-- name: SetUserAdmin :one
+UPDATE users
+SET is_admin = $2, updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, timezone, hashed_password, is_admin, email, last_digest_at, last_digest_post_id
+`
+
+type SetUserAdminParams struct {
+	ID        uuid.UUID
+	IsAdmin   bool
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetUserAdmin(ctx context.Context, arg SetUserAdminParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserAdmin, arg.ID, arg.IsAdmin, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Timezone,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.LastDigestPostID,
+	)
+	return i, err
+}
+
+const setUserLastDigest = `-- name: SetUserLastDigest :exec
+UPDATE users
+SET last_digest_at = $2, last_digest_post_id = $3
+WHERE id = $1
+`
+
+type SetUserLastDigestParams struct {
+	ID               uuid.UUID
+	LastDigestAt     sql.NullTime
+	LastDigestPostID uuid.NullUUID
+}
+
+func (q *Queries) SetUserLastDigest(ctx context.Context, arg SetUserLastDigestParams) error {
+	_, err := q.db.ExecContext(ctx, setUserLastDigest, arg.ID, arg.LastDigestAt, arg.LastDigestPostID)
+	return err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE users
+SET email = $2, updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, timezone, hashed_password, is_admin, email, last_digest_at, last_digest_post_id
+`
+
+type UpdateUserEmailParams struct {
+	ID        uuid.UUID
+	Email     sql.NullString
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserEmail, arg.ID, arg.Email, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Timezone,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.LastDigestPostID,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $2, updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, timezone, hashed_password, is_admin, email, last_digest_at, last_digest_post_id
+`
+
+type UpdateUserPasswordParams struct {
+	ID             uuid.UUID
+	HashedPassword sql.NullString
+	UpdatedAt      time.Time
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.ID, arg.HashedPassword, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Timezone,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.LastDigestPostID,
+	)
+	return i, err
+}
+
+const updateUserTimezone = `-- name: UpdateUserTimezone :one
+UPDATE users
+SET timezone = $2, updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, timezone, hashed_password, is_admin, email, last_digest_at, last_digest_post_id
+`
+
+type UpdateUserTimezoneParams struct {
+	ID        uuid.UUID
+	Timezone  string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateUserTimezone(ctx context.Context, arg UpdateUserTimezoneParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserTimezone, arg.ID, arg.Timezone, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Timezone,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.LastDigestPostID,
+	)
+	return i, err
+}
@@ -0,0 +1,147 @@
+// Package download fetches large files, such as podcast episodes, to disk.
+// Data is written to a ".part" file next to the destination and only
+// renamed into place once complete, so an interrupted download is picked up
+// where it stopped with an HTTP Range request.
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PartSuffix is appended to the destination path while downloading.
+const PartSuffix = ".part"
+
+// Result describes a finished download.
+type Result struct {
+	// Size is the size of the complete file.
+	Size int64
+	// Resumed is the number of bytes that were already on disk from an
+	// earlier attempt.
+	Resumed int64
+}
+
+// ErrIncomplete is returned when the server closes the connection before
+// sending the whole file. The partial file is kept for the next attempt.
+var ErrIncomplete = errors.New("download incomplete")
+
+// File downloads fileURL to path, resuming from path+PartSuffix if an
+// earlier attempt left one behind. Servers that ignore the Range header get
+// the download restarted from the beginning.
+func File(ctx context.Context, fileURL, path string) (Result, error) {
+	// No overall timeout, episodes can take a long time on a slow link.
+	// Only waiting for the server to answer is bounded.
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 30 * time.Second,
+		},
+	}
+
+	partPath := path + PartSuffix
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return Result{}, fmt.Errorf("failed to check partial download: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fileURL, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Add("User-Agent", "gator")
+	if offset > 0 {
+		req.Header.Add("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to fetch file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		start, err := rangeStart(resp.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			return Result{}, fmt.Errorf("server resumed at the wrong offset: %q", resp.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file already holds everything the server has.
+		size, err := rangeSize(resp.Header.Get("Content-Range"))
+		if err != nil || size != offset {
+			return Result{}, fmt.Errorf("partial download does not match the file on the server, remove %s to start over", partPath)
+		}
+		err = os.Rename(partPath, path)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to move download into place: %w", err)
+		}
+		return Result{Size: offset, Resumed: offset}, nil
+	default:
+		return Result{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	file, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to open partial download: %w", err)
+	}
+
+	written, err := io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	// The body reports a connection closed before Content-Length as an
+	// unexpected EOF.
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return Result{}, fmt.Errorf("%w: got %d of %d bytes", ErrIncomplete, written, resp.ContentLength)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to write download: %w", err)
+	}
+	if resp.ContentLength >= 0 && written != resp.ContentLength {
+		return Result{}, fmt.Errorf("%w: got %d of %d bytes", ErrIncomplete, written, resp.ContentLength)
+	}
+
+	err = os.Rename(partPath, path)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to move download into place: %w", err)
+	}
+	return Result{Size: offset + written, Resumed: offset}, nil
+}
+
+// rangeStart returns the first byte of a "bytes start-end/size"
+// Content-Range header.
+func rangeStart(contentRange string) (int64, error) {
+	spec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return 0, fmt.Errorf("invalid content range: %q", contentRange)
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, fmt.Errorf("invalid content range: %q", contentRange)
+	}
+	return strconv.ParseInt(start, 10, 64)
+}
+
+// rangeSize returns the size from a "bytes */size" Content-Range header,
+// which servers send along with 416 Range Not Satisfiable.
+func rangeSize(contentRange string) (int64, error) {
+	_, size, ok := strings.Cut(contentRange, "/")
+	if !ok || size == "*" {
+		return 0, fmt.Errorf("invalid content range: %q", contentRange)
+	}
+	return strconv.ParseInt(size, 10, 64)
+}
@@ -0,0 +1,193 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var content = []byte(strings.Repeat("0123456789", 100))
+
+// serveContent answers Range requests the way a static file server does.
+func serveContent(t *testing.T, ranges *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ranges != nil {
+			*ranges = append(*ranges, r.Header.Get("Range"))
+		}
+		http.ServeContent(w, r, "episode.mp3", time.Time{}, bytes.NewReader(content))
+	}))
+}
+
+func writePart(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path+PartSuffix, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkFile(t *testing.T, path string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("file holds %d bytes that differ from the %d served", len(got), len(content))
+	}
+	if _, err := os.Stat(path + PartSuffix); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("partial file left behind: %v", err)
+	}
+}
+
+func TestFile(t *testing.T) {
+	var ranges []string
+	server := serveContent(t, &ranges)
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "episode.mp3")
+
+	result, err := File(context.Background(), server.URL, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Size: int64(len(content))}) {
+		t.Errorf("result = %+v", result)
+	}
+	if len(ranges) != 1 || ranges[0] != "" {
+		t.Errorf("ranges requested = %q, want none", ranges)
+	}
+	checkFile(t, path)
+}
+
+func TestFileResumes(t *testing.T) {
+	var ranges []string
+	server := serveContent(t, &ranges)
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "episode.mp3")
+	writePart(t, path, content[:300])
+
+	result, err := File(context.Background(), server.URL, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Size: int64(len(content)), Resumed: 300}) {
+		t.Errorf("result = %+v", result)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=300-" {
+		t.Errorf("ranges requested = %q, want bytes=300-", ranges)
+	}
+	checkFile(t, path)
+}
+
+func TestFileRestartsWithoutRangeSupport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "episode.mp3")
+	writePart(t, path, []byte("stale data from another file"))
+
+	result, err := File(context.Background(), server.URL, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Size: int64(len(content))}) {
+		t.Errorf("result = %+v", result)
+	}
+	checkFile(t, path)
+}
+
+func TestFileAlreadyComplete(t *testing.T) {
+	server := serveContent(t, nil)
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "episode.mp3")
+	writePart(t, path, content)
+
+	result, err := File(context.Background(), server.URL, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Size: int64(len(content)), Resumed: int64(len(content))}) {
+		t.Errorf("result = %+v", result)
+	}
+	checkFile(t, path)
+}
+
+func TestFilePartLargerThanFile(t *testing.T) {
+	server := serveContent(t, nil)
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "episode.mp3")
+	writePart(t, path, append(content, "extra"...))
+
+	if _, err := File(context.Background(), server.URL, path); err == nil {
+		t.Fatal("File succeeded with a partial file larger than the download")
+	}
+	if _, err := os.Stat(path + PartSuffix); err != nil {
+		t.Errorf("partial file was not kept: %v", err)
+	}
+}
+
+func TestFileWrongResumeOffset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes 0-999/1000")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(content)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "episode.mp3")
+	writePart(t, path, content[:300])
+
+	if _, err := File(context.Background(), server.URL, path); err == nil {
+		t.Fatal("File accepted a response resumed at the wrong offset")
+	}
+}
+
+func TestFileUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "episode.mp3")
+
+	if _, err := File(context.Background(), server.URL, path); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("File() error = %v, want the status code", err)
+	}
+}
+
+func TestFileIncomplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Promise the whole file, send part of it and hang up.
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(content[:400])
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "episode.mp3")
+
+	_, err := File(context.Background(), server.URL, path)
+	if !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("File() error = %v, want ErrIncomplete", err)
+	}
+	part, err := os.ReadFile(path + PartSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(part, content[:400]) {
+		t.Errorf("partial file holds %d bytes, want the 400 received", len(part))
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("incomplete download was moved into place: %v", err)
+	}
+}
@@ -0,0 +1,326 @@
+// Package htmltext renders post HTML as plain text for the terminal:
+// paragraphs are wrapped to a width, lists get bullets or numbers, quotes
+// are prefixed with "> " and links are numbered and listed as footnotes
+// after the text.
+package htmltext
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skippedTags hold nothing worth reading.
+var skippedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Object: true, atom.Embed: true, atom.Svg: true, atom.Template: true,
+	atom.Head: true, atom.Form: true,
+}
+
+// blockTags start a new paragraph.
+var blockTags = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Figure: true, atom.Figcaption: true, atom.Table: true, atom.Tr: true,
+	atom.Dl: true, atom.Dt: true, atom.Dd: true, atom.Caption: true,
+	atom.Header: true, atom.Footer: true, atom.Aside: true, atom.Main: true,
+}
+
+type block struct {
+	lines []string
+	// tight blocks, the items of a list, are not separated by a blank line.
+	tight bool
+}
+
+type renderer struct {
+	width  int
+	blocks []block
+	inline strings.Builder
+	// prefix starts every line of the current block. marker, if set,
+	// replaces the end of it on the first line, so that list items hang.
+	prefix    string
+	marker    string
+	listDepth int
+	pre       bool
+	links     []string
+}
+
+// Render converts s to text wrapped at width columns. A width of 0 or less
+// leaves lines unwrapped, for callers that wrap text themselves.
+func Render(s string, width int) string {
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(s), body)
+	if err != nil {
+		return strings.TrimSpace(s)
+	}
+
+	r := &renderer{width: width}
+	for _, n := range nodes {
+		r.render(n)
+	}
+	r.flush()
+
+	var out strings.Builder
+	for i, b := range r.blocks {
+		if i > 0 {
+			out.WriteString("\n")
+			if !b.tight || !r.blocks[i-1].tight {
+				out.WriteString("\n")
+			}
+		}
+		out.WriteString(strings.Join(b.lines, "\n"))
+	}
+	if len(r.links) > 0 {
+		out.WriteString("\n\n")
+		for i, link := range r.links {
+			fmt.Fprintf(&out, "[%d] %s\n", i+1, link)
+		}
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// Summary returns the text of s on a single line, without the link list
+// Render adds, for places that show an excerpt.
+func Summary(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(s), body)
+	if err != nil {
+		return strings.Join(strings.Fields(s), " ")
+	}
+
+	var out strings.Builder
+	for _, n := range nodes {
+		writeSummary(&out, n)
+	}
+	return strings.Join(strings.Fields(out.String()), " ")
+}
+
+func writeSummary(w *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.WriteString(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if skippedTags[n.DataAtom] {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeSummary(w, c)
+	}
+	// Words in separate blocks or cells must not run together.
+	if blockTags[n.DataAtom] || n.DataAtom == atom.Br || n.DataAtom == atom.Li || n.DataAtom == atom.Td || n.DataAtom == atom.Th {
+		w.WriteString(" ")
+	}
+}
+
+func (r *renderer) render(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.inline.WriteString(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if skippedTags[n.DataAtom] {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		r.inline.WriteString("\n")
+	case atom.Hr:
+		r.flush()
+		r.blocks = append(r.blocks, block{lines: []string{r.prefix + "----"}})
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			fmt.Fprintf(&r.inline, " [image: %s] ", alt)
+		}
+	case atom.A:
+		start := r.inline.Len()
+		r.renderChildren(n)
+		r.footnote(attr(n, "href"), r.inline.String()[start:])
+	case atom.Td, atom.Th:
+		if n.PrevSibling != nil {
+			r.inline.WriteString(" | ")
+		}
+		r.renderChildren(n)
+	case atom.Pre:
+		r.flush()
+		r.pre = true
+		r.renderChildren(n)
+		r.flush()
+		r.pre = false
+	case atom.Blockquote:
+		r.flush()
+		prefix := r.prefix
+		r.prefix += "> "
+		r.renderChildren(n)
+		r.flush()
+		r.prefix = prefix
+	case atom.Ul, atom.Ol:
+		r.flush()
+		r.listDepth++
+		number := 1
+		if n.DataAtom == atom.Ol {
+			fmt.Sscanf(attr(n, "start"), "%d", &number)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+				r.render(c)
+				continue
+			}
+			marker := "* "
+			if n.DataAtom == atom.Ol {
+				marker = fmt.Sprintf("%d. ", number)
+				number++
+			}
+			r.listItem(c, marker)
+		}
+		r.flush()
+		r.listDepth--
+	case atom.Li:
+		// A list item outside of a list.
+		r.listItem(n, "* ")
+	default:
+		if blockTags[n.DataAtom] {
+			r.flush()
+			r.renderChildren(n)
+			r.flush()
+			return
+		}
+		r.renderChildren(n)
+	}
+}
+
+func (r *renderer) renderChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.render(c)
+	}
+}
+
+func (r *renderer) listItem(n *html.Node, marker string) {
+	r.flush()
+	prefix := r.prefix
+	r.prefix += strings.Repeat(" ", len(marker))
+	r.marker = marker
+	r.renderChildren(n)
+	r.flush()
+	r.prefix = prefix
+	r.marker = ""
+}
+
+// footnote numbers href and appends the number to the link text. Links
+// whose text already is the URL, and links within the page, are left as
+// they are.
+func (r *renderer) footnote(href, text string) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return
+	}
+	text = strings.TrimSpace(text)
+	if text == href || "mailto:"+text == href {
+		return
+	}
+	number := 0
+	for i, link := range r.links {
+		if link == href {
+			number = i + 1
+			break
+		}
+	}
+	if number == 0 {
+		r.links = append(r.links, href)
+		number = len(r.links)
+	}
+	fmt.Fprintf(&r.inline, "[%d]", number)
+}
+
+// flush ends the current block, wrapping the text collected since the
+// previous one.
+func (r *renderer) flush() {
+	text := r.inline.String()
+	r.inline.Reset()
+
+	var lines []string
+	if r.pre {
+		text = strings.Trim(text, "\n")
+		if text == "" {
+			return
+		}
+		for _, line := range strings.Split(text, "\n") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	} else {
+		width := r.width
+		if width > 0 {
+			// Deeply nested text still gets some room.
+			width = max(width-utf8.RuneCountInString(r.prefix), 20)
+		}
+		for _, segment := range strings.Split(text, "\n") {
+			lines = append(lines, wrap(strings.Fields(segment), width)...)
+		}
+		for len(lines) > 0 && lines[0] == "" {
+			lines = lines[1:]
+		}
+		for len(lines) > 0 && lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		if len(lines) == 0 {
+			return
+		}
+	}
+
+	for i, line := range lines {
+		prefix := r.prefix
+		if i == 0 && r.marker != "" {
+			prefix = prefix[:len(prefix)-len(r.marker)] + r.marker
+		}
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	r.marker = ""
+	r.blocks = append(r.blocks, block{lines: lines, tight: r.listDepth > 0})
+}
+
+// wrap fills words into lines of at most width runes. Words longer than
+// width, such as URLs, get a line of their own.
+func wrap(words []string, width int) []string {
+	if len(words) == 0 {
+		return []string{""}
+	}
+	if width <= 0 {
+		return []string{strings.Join(words, " ")}
+	}
+	var lines []string
+	line := words[0]
+	for _, word := range words[1:] {
+		if utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line += " " + word
+	}
+	return append(lines, line)
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
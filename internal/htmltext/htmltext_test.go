@@ -0,0 +1,60 @@
+package htmltext
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		want  string
+	}{
+		{"empty", "", 80, ""},
+		{"plain text", "just text", 80, "just text"},
+		{"paragraphs", "<p>one</p><p>two</p>", 80, "one\n\ntwo"},
+		{"wrapped", "<p>aaaaa bbbbb ccccc ddddd eeeee</p>", 20, "aaaaa bbbbb ccccc\nddddd eeeee"},
+		{"unwrapped at zero width", "<p>aaa bbb ccc ddd</p>", 0, "aaa bbb ccc ddd"},
+		{"line break", "one<br>two", 80, "one\ntwo"},
+		{"unordered list", "<ul><li>a</li><li>b</li></ul>", 80, "* a\n* b"},
+		{"ordered list with start", `<ol start="3"><li>a</li><li>b</li></ol>`, 80, "3. a\n4. b"},
+		{"hanging list item", "<ul><li>aaaaa bbbbb ccccc ddddd</li></ul>", 22, "* aaaaa bbbbb ccccc\n  ddddd"},
+		{"quote", "<blockquote><p>said</p></blockquote>", 80, "> said"},
+		{"pre keeps lines", "<pre>a  b\n  c</pre>", 80, "a  b\n  c"},
+		{"link footnote", `see <a href="https://a.example">this</a> and <a href="https://a.example">that</a>`, 80, "see this[1] and that[1]\n\n[1] https://a.example"},
+		{"bare link", `<a href="https://a.example">https://a.example</a>`, 80, "https://a.example"},
+		{"anchor link", `<a href="#top">top</a>`, 80, "top"},
+		{"image alt", `<img src="x.png" alt="a cat">`, 80, "[image: a cat]"},
+		{"table", "<table><tr><td>a</td><td>b</td></tr><tr><td>c</td><td>d</td></tr></table>", 80, "a | b\n\nc | d"},
+		{"script skipped", "<script>x()</script>text", 80, "text"},
+		{"rule", "a<hr>b", 80, "a\n\n----\n\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.in, tt.width); got != tt.want {
+				t.Errorf("Render(%q, %d)\n got  %q\n want %q", tt.in, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"plain  text\n here", "plain text here"},
+		{"<p>one</p><p>two</p>", "one two"},
+		{"<b>bold</b>text", "boldtext"},
+		{`read <a href="https://a.example">more</a>`, "read more"},
+		{"a &amp; b &lt;c&gt;", "a & b <c>"},
+		{"<ul><li>a</li><li>b</li></ul>", "a b"},
+		{"<style>p{}</style><script>x()</script>text", "text"},
+		{"line<br>break", "line break"},
+	}
+	for _, tt := range tests {
+		if got := Summary(tt.in); got != tt.want {
+			t.Errorf("Summary(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
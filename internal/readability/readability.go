@@ -0,0 +1,399 @@
+// Package readability extracts the main article from a web page, in the
+// spirit of Arc90's Readability: paragraphs are scored by how much prose
+// they hold, the scores bubble up to their containers, and the best
+// container and its related siblings make up the article.
+package readability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Romasav/gator/internal/sanitize"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// maxPageSize bounds how much of a page is read, so a huge or endless
+// response can not exhaust memory.
+const maxPageSize = 5 << 20
+
+// Article is the extracted main content of a page.
+type Article struct {
+	Title string
+	// HTML is the cleaned article markup: only structural tags, no scripts,
+	// styles or attributes other than links and image sources, with every
+	// URL made absolute.
+	HTML string
+	Text string
+}
+
+var ErrNoContent = errors.New("no article content found")
+
+// Fetch downloads pageURL and extracts its article.
+func Fetch(ctx context.Context, pageURL string) (Article, error) {
+	client := &http.Client{
+		Timeout: 20 * time.Second,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return Article{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Add("User-Agent", "gator")
+	req.Header.Add("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Article{}, fmt.Errorf("failed to fetch page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Article{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return Article{}, fmt.Errorf("not an html page: %s", mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxPageSize), contentType)
+	if err != nil {
+		return Article{}, fmt.Errorf("failed to decode page: %w", err)
+	}
+	return Extract(body, resp.Request.URL)
+}
+
+// Extract parses the page in r and returns its article. Relative links are
+// resolved against pageURL.
+func Extract(r io.Reader, pageURL *url.URL) (Article, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return Article{}, fmt.Errorf("failed to parse page: %w", err)
+	}
+
+	base := pageURL
+	if href := findBaseHref(doc); href != "" {
+		if u, err := pageURL.Parse(href); err == nil {
+			base = u
+		}
+	}
+
+	article := Article{Title: collapseSpace(textContent(findFirst(doc, atom.Title)))}
+
+	removeUnlikely(doc)
+	content := topContent(doc)
+	if content == nil {
+		return article, ErrNoContent
+	}
+
+	var out strings.Builder
+	for _, n := range content {
+		sanitize.Write(&out, n, base)
+	}
+	article.HTML = strings.TrimSpace(out.String())
+
+	var text strings.Builder
+	for _, n := range content {
+		writeText(&text, n)
+	}
+	article.Text = tidyText(text.String())
+	if article.Text == "" {
+		return article, ErrNoContent
+	}
+	return article, nil
+}
+
+var (
+	unlikelyPattern = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|gdpr|header|legends|menu|modal|nav|newsletter|pager|pagination|popup|promo|related|remark|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|tags|tool|widget|\bad-|ads\b`)
+	maybePattern    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow|story|entry|post`)
+	positivePattern = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|story|text|blog`)
+	negativePattern = regexp.MustCompile(`(?i)-ad-|hidden|^hid$|\bhid\b|banner|combx|comment|com-|contact|footer|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// droppedTags never hold article text.
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Form: true, atom.Button: true, atom.Input: true, atom.Select: true,
+	atom.Textarea: true, atom.Nav: true, atom.Aside: true, atom.Footer: true,
+	atom.Header: true, atom.Svg: true, atom.Canvas: true, atom.Object: true,
+	atom.Embed: true, atom.Link: true, atom.Meta: true, atom.Template: true,
+}
+
+func removeUnlikely(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode || (c.Type == html.ElementNode && unlikely(c)) {
+			n.RemoveChild(c)
+		} else {
+			removeUnlikely(c)
+		}
+		c = next
+	}
+}
+
+func unlikely(n *html.Node) bool {
+	if droppedTags[n.DataAtom] {
+		return true
+	}
+	if n.DataAtom == atom.Body || n.DataAtom == atom.Html || n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+		return false
+	}
+	if attr(n, "hidden") != "" || attr(n, "aria-hidden") == "true" || strings.Contains(strings.ReplaceAll(attr(n, "style"), " ", ""), "display:none") {
+		return true
+	}
+	match := attr(n, "class") + " " + attr(n, "id")
+	return unlikelyPattern.MatchString(match) && !maybePattern.MatchString(match)
+}
+
+// topContent picks the highest scoring container and returns it together
+// with the siblings that look like part of the same article.
+func topContent(doc *html.Node) []*html.Node {
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+
+	walk(doc, func(n *html.Node) {
+		if n.Type != html.ElementNode || !scoredTag(n) {
+			return
+		}
+		text := collapseSpace(textContent(n))
+		if len(text) < 25 {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		for level, ancestor := 0, n.Parent; ancestor != nil && level < 3; level, ancestor = level+1, ancestor.Parent {
+			if ancestor.Type != html.ElementNode {
+				break
+			}
+			if _, ok := scores[ancestor]; !ok {
+				scores[ancestor] = initialScore(ancestor)
+				candidates = append(candidates, ancestor)
+			}
+			switch level {
+			case 0:
+				scores[ancestor] += score
+			case 1:
+				scores[ancestor] += score / 2
+			default:
+				scores[ancestor] += score / float64(level*3)
+			}
+		}
+	})
+
+	var top *html.Node
+	topScore := 0.0
+	for _, c := range candidates {
+		scores[c] *= 1 - linkDensity(c)
+		if top == nil || scores[c] > topScore {
+			top, topScore = c, scores[c]
+		}
+	}
+	if top == nil {
+		if body := findFirst(doc, atom.Body); body != nil {
+			return []*html.Node{body}
+		}
+		return nil
+	}
+	if top.Parent == nil || top.Parent.Type != html.ElementNode {
+		return []*html.Node{top}
+	}
+
+	threshold := math.Max(10, topScore*0.2)
+	topClass := attr(top, "class")
+	var content []*html.Node
+	for s := top.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s == top {
+			content = append(content, s)
+			continue
+		}
+		if s.Type != html.ElementNode {
+			continue
+		}
+		bonus := 0.0
+		if topClass != "" && attr(s, "class") == topClass {
+			bonus = topScore * 0.2
+		}
+		if score, ok := scores[s]; ok && score+bonus >= threshold {
+			content = append(content, s)
+			continue
+		}
+		if s.DataAtom == atom.P {
+			text := collapseSpace(textContent(s))
+			density := linkDensity(s)
+			if (len(text) > 80 && density < 0.25) || (len(text) > 0 && density == 0 && strings.HasSuffix(text, ".")) {
+				content = append(content, s)
+			}
+		}
+	}
+	return content
+}
+
+func scoredTag(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td, atom.Blockquote, atom.Li:
+		return true
+	case atom.Div, atom.Section:
+		// Divs used as paragraphs: no block level children of their own.
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && blockTags[c.DataAtom] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+var blockTags = map[atom.Atom]bool{
+	atom.Blockquote: true, atom.Dl: true, atom.Div: true,
+	atom.Img: true, atom.Ol: true, atom.P: true, atom.Pre: true,
+	atom.Table: true, atom.Ul: true, atom.Section: true, atom.Article: true,
+	atom.Figure: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+func initialScore(n *html.Node) float64 {
+	score := 0.0
+	switch n.DataAtom {
+	case atom.Article:
+		score += 10
+	case atom.Div, atom.Main, atom.Section:
+		score += 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score += 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= 5
+	}
+	for _, value := range []string{attr(n, "class"), attr(n, "id")} {
+		if value == "" {
+			continue
+		}
+		if negativePattern.MatchString(value) {
+			score -= 25
+		}
+		if positivePattern.MatchString(value) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// linkDensity is the share of n's text that sits inside links.
+func linkDensity(n *html.Node) float64 {
+	total := len(collapseSpace(textContent(n)))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	walk(n, func(c *html.Node) {
+		if c.Type == html.ElementNode && c.DataAtom == atom.A {
+			linked += len(collapseSpace(textContent(c)))
+		}
+	})
+	return math.Min(float64(linked)/float64(total), 1)
+}
+
+// paragraphTags end a line of text in the plain text rendering.
+var paragraphTags = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Tr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Figure: true, atom.Section: true,
+	atom.Article: true, atom.Dt: true, atom.Dd: true, atom.Table: true, atom.Ul: true, atom.Ol: true,
+}
+
+func writeText(w *strings.Builder, n *html.Node) {
+	if n.Type == html.TextNode {
+		w.WriteString(n.Data)
+		return
+	}
+	if n.Type != html.ElementNode {
+		return
+	}
+	if n.DataAtom == atom.Li {
+		w.WriteString("\n- ")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeText(w, c)
+	}
+	if paragraphTags[n.DataAtom] {
+		w.WriteString("\n\n")
+	}
+}
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// tidyText collapses the whitespace inside lines and keeps at most one
+// blank line between paragraphs.
+func tidyText(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = collapseSpace(line)
+	}
+	s = strings.Join(lines, "\n")
+	return strings.TrimSpace(blankLines.ReplaceAllString(s, "\n\n"))
+}
+
+func walk(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+func findFirst(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findFirst(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func findBaseHref(doc *html.Node) string {
+	if base := findFirst(doc, atom.Base); base != nil {
+		return attr(base, "href")
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+// Package sanitize reduces untrusted HTML, from feed items or extracted
+// articles, to an allowlist of formatting tags and attributes, so it can be
+// shown without running scripts or loading anything but links and images.
+package sanitize
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// droppedTags are removed together with everything inside them.
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Frame: true, atom.Frameset: true, atom.Object: true, atom.Embed: true,
+	atom.Applet: true, atom.Form: true, atom.Button: true, atom.Input: true,
+	atom.Select: true, atom.Textarea: true, atom.Svg: true, atom.Math: true,
+	atom.Canvas: true, atom.Template: true, atom.Head: true, atom.Title: true,
+	atom.Meta: true, atom.Link: true, atom.Base: true,
+}
+
+// allowedTags are kept; other elements are replaced by their children.
+var allowedTags = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true, atom.A: true, atom.Img: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Code: true, atom.Em: true, atom.Strong: true,
+	atom.B: true, atom.I: true, atom.U: true, atom.S: true, atom.Sub: true, atom.Sup: true,
+	atom.Figure: true, atom.Figcaption: true, atom.Table: true, atom.Thead: true,
+	atom.Tbody: true, atom.Tr: true, atom.Th: true, atom.Td: true, atom.Caption: true,
+}
+
+// allowedAttrs are the attributes kept per tag; all others, including
+// every style and event handler, are dropped.
+var allowedAttrs = map[atom.Atom][]string{
+	atom.A:          {"href", "title"},
+	atom.Img:        {"src", "alt", "title"},
+	atom.Blockquote: {"cite"},
+	atom.Ol:         {"start"},
+	atom.Td:         {"colspan", "rowspan"},
+	atom.Th:         {"colspan", "rowspan"},
+}
+
+// urlAttrs hold links, which must pass safeURL.
+var urlAttrs = map[string]bool{"href": true, "src": true, "cite": true}
+
+// HTML sanitizes the HTML fragment s. Relative links and image sources are
+// resolved against base, unless it is nil. Text outside of tags is kept,
+// which makes it safe to pass text that is not HTML at all.
+func HTML(s string, base *url.URL) string {
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(s), body)
+	if err != nil {
+		return html.EscapeString(s)
+	}
+
+	var out strings.Builder
+	for _, n := range nodes {
+		Write(&out, n, base)
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// Write writes the sanitized form of n and its children to w, for callers
+// that already hold a parsed document.
+func Write(w *strings.Builder, n *html.Node, base *url.URL) {
+	switch n.Type {
+	case html.TextNode:
+		w.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if droppedTags[n.DataAtom] {
+		return
+	}
+
+	allowed := allowedTags[n.DataAtom]
+	if allowed {
+		w.WriteString("<" + n.Data)
+		for _, key := range allowedAttrs[n.DataAtom] {
+			value, ok := attr(n, key)
+			if key == "src" && strings.TrimSpace(value) == "" {
+				// Lazy loaded images keep the real source elsewhere.
+				value, ok = attr(n, "data-src")
+			}
+			if !ok {
+				continue
+			}
+			if urlAttrs[key] {
+				if !safeURL(value) {
+					continue
+				}
+				value = resolveURL(base, value)
+			}
+			fmt.Fprintf(w, ` %s="%s"`, key, html.EscapeString(strings.TrimSpace(value)))
+		}
+		w.WriteString(">")
+		if n.DataAtom == atom.Img || n.DataAtom == atom.Br || n.DataAtom == atom.Hr {
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		Write(w, c, base)
+	}
+	if allowed {
+		w.WriteString("</" + n.Data + ">")
+	}
+}
+
+// safeURL reports whether ref is a relative, http(s) or mailto URL. This
+// keeps out javascript: and data: links.
+func safeURL(ref string) bool {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// resolveURL resolves ref against base. Without a base, or if ref can not
+// be parsed, it is returned as it is.
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if base == nil || ref == "" {
+		return ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,49 @@
+package sanitize
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/post/")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "  ", ""},
+		{"plain text", "Tom & Jerry <3", "Tom &amp; Jerry &lt;3"},
+		{"formatting kept", "<p>Hello <strong>world</strong></p>", "<p>Hello <strong>world</strong></p>"},
+		{"script dropped with content", `<p>a</p><script>alert(1)</script><p>b</p>`, "<p>a</p><p>b</p>"},
+		{"style dropped with content", `<style>p{color:red}</style>text`, "text"},
+		{"unknown tags unwrapped", `<div><span class="x">text</span></div>`, "text"},
+		{"event handlers and styles dropped", `<p onclick="evil()" style="color:red">text</p>`, "<p>text</p>"},
+		{"javascript link dropped", `<a href="javascript:alert(1)">x</a>`, "<a>x</a>"},
+		{"data image dropped", `<img src="data:image/png;base64,AAAA" alt="a">`, `<img alt="a">`},
+		{"relative link resolved", `<a href="../other" title="t">x</a>`, `<a href="https://example.com/blog/other" title="t">x</a>`},
+		{"absolute link kept", `<a href="http://other.org/a?b=1&amp;c=2">x</a>`, `<a href="http://other.org/a?b=1&amp;c=2">x</a>`},
+		{"mailto kept", `<a href="mailto:me@example.com">me</a>`, `<a href="mailto:me@example.com">me</a>`},
+		{"lazy image", `<img src="" data-src="/img.png" alt="i">`, `<img src="https://example.com/img.png" alt="i">`},
+		{"iframe dropped", `<iframe src="https://evil.example"></iframe>after`, "after"},
+		{"quote cite and list start", `<blockquote cite="/src">q</blockquote><ol start="3"><li>x</li></ol>`, `<blockquote cite="https://example.com/src">q</blockquote><ol start="3"><li>x</li></ol>`},
+		{"attribute values escaped", `<img alt="&quot;><script>" src="/a.png">`, `<img src="https://example.com/a.png" alt="&#34;&gt;&lt;script&gt;">`},
+		{"table cells keep spans", `<table><tr><td colspan="2" width="5">x</td></tr></table>`, `<table><tbody><tr><td colspan="2">x</td></tr></tbody></table>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTML(tt.in, base); got != tt.want {
+				t.Errorf("HTML(%q)\n got  %q\n want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLWithoutBase(t *testing.T) {
+	got := HTML(`<a href="/relative">x</a>`, nil)
+	want := `<a href="/relative">x</a>`
+	if got != want {
+		t.Errorf("HTML without base = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const apiTokenColumns = `id, user_id, name, token_hash, created_at, last_used_at`
+
+func scanAPIToken(row scanner) (database.ApiToken, error) {
+	var i database.ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const createAPIToken = `
+INSERT INTO api_tokens (id, user_id, name, token_hash, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5)
+RETURNING ` + apiTokenColumns
+
+func (q *Queries) CreateAPIToken(ctx context.Context, arg database.CreateAPITokenParams) (database.ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, createAPIToken,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.TokenHash,
+		arg.CreatedAt,
+	)
+	return scanAPIToken(row)
+}
+
+const getAPITokensForUser = `
+SELECT ` + apiTokenColumns + ` FROM api_tokens
+WHERE user_id = ?1
+ORDER BY created_at
+`
+
+func (q *Queries) GetAPITokensForUser(ctx context.Context, userID uuid.UUID) ([]database.ApiToken, error) {
+	rows, err := q.db.QueryContext(ctx, getAPITokensForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.ApiToken
+	for rows.Next() {
+		i, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteAPIToken = `
+DELETE FROM api_tokens
+WHERE id = ?1 AND user_id = ?2
+`
+
+func (q *Queries) DeleteAPIToken(ctx context.Context, arg database.DeleteAPITokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAPIToken, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteAPITokensByName = `
+DELETE FROM api_tokens
+WHERE user_id = ?1 AND name = ?2
+`
+
+func (q *Queries) DeleteAPITokensByName(ctx context.Context, arg database.DeleteAPITokensByNameParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAPITokensByName, arg.UserID, arg.Name)
+	return err
+}
+
+const getUserByAPIToken = `
+SELECT ` + userColumns + `
+FROM api_tokens
+JOIN users ON users.id = api_tokens.user_id
+WHERE api_tokens.token_hash = ?1
+`
+
+func (q *Queries) GetUserByAPIToken(ctx context.Context, tokenHash string) (database.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, getUserByAPIToken, tokenHash))
+}
+
+const touchAPIToken = `
+UPDATE api_tokens
+SET last_used_at = ?2
+WHERE token_hash = ?1
+`
+
+func (q *Queries) TouchAPIToken(ctx context.Context, arg database.TouchAPITokenParams) error {
+	_, err := q.db.ExecContext(ctx, touchAPIToken, arg.TokenHash, arg.LastUsedAt)
+	return err
+}
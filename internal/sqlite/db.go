@@ -0,0 +1,41 @@
+// Package sqlite implements database.Querier on top of SQLite. The queries
+// mirror sql/queries and the schema lives in sql/sqlite/schema; keep all
+// three in sync when a query changes.
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+)
+
+func New(db database.DBTX) *Queries {
+	return &Queries{db: db}
+}
+
+type Queries struct {
+	db database.DBTX
+}
+
+func (q *Queries) WithTx(tx *sql.Tx) *Queries {
+	return &Queries{
+		db: tx,
+	}
+}
+
+var _ database.Querier = (*Queries)(nil)
+
+// scanner is satisfied by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// timeFormat is how the driver stores time values with _time_format=sqlite.
+const timeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// parseTime parses a time stored as text, for expressions such as MAX(...)
+// where the driver cannot tell that the value is a time.
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(timeFormat, s)
+}
@@ -0,0 +1,170 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const createEpisode = `
+INSERT INTO episodes (post_id, media_url, media_type, media_length, duration_seconds, episode_number, season_number, image_url, explicit, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+ON CONFLICT (post_id) DO NOTHING
+`
+
+func (q *Queries) CreateEpisode(ctx context.Context, arg database.CreateEpisodeParams) error {
+	_, err := q.db.ExecContext(ctx, createEpisode,
+		arg.PostID,
+		arg.MediaUrl,
+		arg.MediaType,
+		arg.MediaLength,
+		arg.DurationSeconds,
+		arg.EpisodeNumber,
+		arg.SeasonNumber,
+		arg.ImageUrl,
+		arg.Explicit,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const getPodcastsForUser = `
+SELECT
+    feeds.id AS feed_id,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    COUNT(episodes.post_id) AS episode_count,
+    COUNT(post_states.played_at) AS played_count,
+    COUNT(post_states.downloaded_at) AS downloaded_count,
+    MAX(posts.created_at) AS newest_created_at
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+JOIN posts ON posts.feed_id = feeds.id
+JOIN episodes ON episodes.post_id = posts.id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = ?1
+AND post_states.muted_at IS NULL
+GROUP BY feeds.id, feeds.name, feeds.url
+ORDER BY feeds.name
+`
+
+func (q *Queries) GetPodcastsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetPodcastsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPodcastsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.GetPodcastsForUserRow
+	for rows.Next() {
+		var i database.GetPodcastsForUserRow
+		var newest string
+		if err := rows.Scan(
+			&i.FeedID,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.EpisodeCount,
+			&i.PlayedCount,
+			&i.DownloadedCount,
+			&newest,
+		); err != nil {
+			return nil, err
+		}
+		// Aggregates lose the column type, so the driver hands back text.
+		if i.NewestCreatedAt, err = parseTime(newest); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEpisodesForUser = `
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    episodes.media_url,
+    episodes.media_type,
+    episodes.media_length,
+    episodes.duration_seconds,
+    episodes.episode_number,
+    episodes.season_number,
+    episodes.image_url,
+    episodes.explicit,
+    post_states.played_at,
+    post_states.downloaded_at,
+    post_states.download_path
+FROM posts
+JOIN episodes ON episodes.post_id = posts.id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = ?1
+AND post_states.muted_at IS NULL
+AND (?2 IS NULL OR posts.id = ?2)
+AND (?3 IS NULL OR feeds.url = ?3)
+AND (NOT ?4 OR post_states.played_at IS NULL)
+AND (NOT ?5 OR post_states.downloaded_at IS NOT NULL)
+AND (NOT ?6 OR post_states.downloaded_at IS NULL)
+ORDER BY posts.published_at DESC
+LIMIT ?7
+`
+
+func (q *Queries) GetEpisodesForUser(ctx context.Context, arg database.GetEpisodesForUserParams) ([]database.GetEpisodesForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEpisodesForUser,
+		arg.UserID,
+		arg.PostID,
+		arg.FeedUrl,
+		arg.UnplayedOnly,
+		arg.DownloadedOnly,
+		arg.NotDownloadedOnly,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.GetEpisodesForUserRow
+	for rows.Next() {
+		var i database.GetEpisodesForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.MediaUrl,
+			&i.MediaType,
+			&i.MediaLength,
+			&i.DurationSeconds,
+			&i.EpisodeNumber,
+			&i.SeasonNumber,
+			&i.ImageUrl,
+			&i.Explicit,
+			&i.PlayedAt,
+			&i.DownloadedAt,
+			&i.DownloadPath,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
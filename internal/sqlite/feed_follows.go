@@ -0,0 +1,150 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const feedFollowColumns = `feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.folder`
+
+// SQLite does not allow INSERT inside a CTE, so the follow is inserted first
+// and read back joined with its feed and user.
+const createFeedFollow = `
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+VALUES (?1, ?2, ?3, ?4, ?5)
+`
+
+const getCreatedFeedFollow = `
+SELECT
+    ` + feedFollowColumns + `,
+    feeds.name AS feed_name,
+    users.name AS user_name
+FROM feed_follows
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+INNER JOIN users ON users.id = feed_follows.user_id
+WHERE feed_follows.id = ?1
+`
+
+func (q *Queries) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	_, err := q.db.ExecContext(ctx, createFeedFollow,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+	)
+	if err != nil {
+		return database.CreateFeedFollowRow{}, err
+	}
+	row := q.db.QueryRowContext(ctx, getCreatedFeedFollow, arg.ID)
+	var i database.CreateFeedFollowRow
+	err = row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Folder,
+		&i.FeedName,
+		&i.UserName,
+	)
+	return i, err
+}
+
+const deleteFeedFollowByUserAndFeedURL = `
+DELETE FROM feed_follows
+WHERE user_id = ?1
+AND feed_id IN (SELECT id FROM feeds WHERE url = ?2)
+`
+
+func (q *Queries) DeleteFeedFollowByUserAndFeedURL(ctx context.Context, arg database.DeleteFeedFollowByUserAndFeedURLParams) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedFollowByUserAndFeedURL, arg.UserID, arg.Url)
+	return err
+}
+
+const getFeedFollow = `
+SELECT ` + feedFollowColumns + ` FROM feed_follows
+WHERE user_id = ?1 AND feed_id = ?2
+`
+
+func (q *Queries) GetFeedFollow(ctx context.Context, arg database.GetFeedFollowParams) (database.FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollow, arg.UserID, arg.FeedID)
+	var i database.FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Folder,
+	)
+	return i, err
+}
+
+const getFeedFollowsForUser = `
+SELECT
+    ` + feedFollowColumns + `,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    users.name AS user_name
+FROM feed_follows
+INNER JOIN feeds ON feed_follows.feed_id = feeds.id
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.user_id = ?1
+ORDER BY feed_follows.folder, feeds.name
+`
+
+func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFollowsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.GetFeedFollowsForUserRow
+	for rows.Next() {
+		var i database.GetFeedFollowsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Folder,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.UserName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setFeedFollowFolder = `
+UPDATE feed_follows
+SET folder = ?3, updated_at = ?4
+WHERE user_id = ?1
+AND feed_id IN (SELECT id FROM feeds WHERE url = ?2)
+`
+
+func (q *Queries) SetFeedFollowFolder(ctx context.Context, arg database.SetFeedFollowFolderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowFolder,
+		arg.UserID,
+		arg.Url,
+		arg.Folder,
+		arg.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
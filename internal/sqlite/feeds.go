@@ -0,0 +1,178 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const feedColumns = `feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.fetch_full_content, feeds.last_parse_warning`
+
+func scanFeed(row scanner) (database.Feed, error) {
+	var i database.Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.FetchFullContent,
+		&i.LastParseWarning,
+	)
+	return i, err
+}
+
+func (q *Queries) queryFeeds(ctx context.Context, query string, args ...interface{}) ([]database.Feed, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.Feed
+	for rows.Next() {
+		i, err := scanFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createFeed = `
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+RETURNING ` + feedColumns
+
+func (q *Queries) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	row := q.db.QueryRowContext(ctx, createFeed,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.Url,
+		arg.UserID,
+	)
+	return scanFeed(row)
+}
+
+const getFeedByURL = `
+SELECT ` + feedColumns + ` FROM feeds WHERE url = ?1 LIMIT 1
+`
+
+func (q *Queries) GetFeedByURL(ctx context.Context, url string) (database.Feed, error) {
+	return scanFeed(q.db.QueryRowContext(ctx, getFeedByURL, url))
+}
+
+const deleteFeed = `
+DELETE FROM feeds WHERE id = ?1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
+// Hands every feed the user added to the longest-standing other follower.
+// Feeds nobody else follows keep their owner until the user is deleted,
+// at which point they fall back to the system (NULL owner).
+const reassignFeedsOfUser = `
+UPDATE feeds
+SET user_id = (
+        SELECT feed_follows.user_id FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id
+        AND feed_follows.user_id <> ?1
+        ORDER BY feed_follows.created_at
+        LIMIT 1
+    ),
+    updated_at = ?2
+WHERE feeds.user_id = ?1
+AND EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id
+    AND feed_follows.user_id <> ?1
+)
+`
+
+func (q *Queries) ReassignFeedsOfUser(ctx context.Context, arg database.ReassignFeedsOfUserParams) error {
+	_, err := q.db.ExecContext(ctx, reassignFeedsOfUser, arg.UserID, arg.UpdatedAt)
+	return err
+}
+
+const deleteAllFeeds = `
+DELETE FROM feeds
+`
+
+func (q *Queries) DeleteAllFeeds(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllFeeds)
+	return err
+}
+
+const setFeedFetchFullContent = `
+UPDATE feeds
+SET fetch_full_content = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING ` + feedColumns
+
+func (q *Queries) SetFeedFetchFullContent(ctx context.Context, arg database.SetFeedFetchFullContentParams) (database.Feed, error) {
+	return scanFeed(q.db.QueryRowContext(ctx, setFeedFetchFullContent, arg.ID, arg.FetchFullContent, arg.UpdatedAt))
+}
+
+const transferFeed = `
+UPDATE feeds
+SET user_id = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING ` + feedColumns
+
+func (q *Queries) TransferFeed(ctx context.Context, arg database.TransferFeedParams) (database.Feed, error) {
+	return scanFeed(q.db.QueryRowContext(ctx, transferFeed, arg.ID, arg.UserID, arg.UpdatedAt))
+}
+
+const getFeeds = `
+SELECT ` + feedColumns + ` FROM feeds
+`
+
+func (q *Queries) GetFeeds(ctx context.Context) ([]database.Feed, error) {
+	return q.queryFeeds(ctx, getFeeds)
+}
+
+const getNextFeedToFetch = `
+SELECT ` + feedColumns + ` FROM feeds
+ORDER BY last_fetched_at NULLS FIRST
+LIMIT 1
+`
+
+func (q *Queries) GetNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	return scanFeed(q.db.QueryRowContext(ctx, getNextFeedToFetch))
+}
+
+const markFeedFetched = `
+UPDATE feeds
+SET last_fetched_at = ?1, updated_at = ?1
+WHERE id = ?2
+`
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, arg database.MarkFeedFetchedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.FetchedAt, arg.ID)
+	return err
+}
+
+const setFeedParseWarning = `
+UPDATE feeds
+SET last_parse_warning = ?2
+WHERE id = ?1
+`
+
+func (q *Queries) SetFeedParseWarning(ctx context.Context, arg database.SetFeedParseWarningParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedParseWarning, arg.ID, arg.LastParseWarning)
+	return err
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const filterRuleColumns = `filter_rules.id, filter_rules.user_id, filter_rules.field, filter_rules.pattern, filter_rules.is_regex, filter_rules.action, filter_rules.tag, filter_rules.created_at`
+
+func scanFilterRule(row scanner) (database.FilterRule, error) {
+	var i database.FilterRule
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Field,
+		&i.Pattern,
+		&i.IsRegex,
+		&i.Action,
+		&i.Tag,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+func (q *Queries) queryFilterRules(ctx context.Context, query string, args ...interface{}) ([]database.FilterRule, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.FilterRule
+	for rows.Next() {
+		i, err := scanFilterRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createFilterRule = `
+INSERT INTO filter_rules (id, user_id, field, pattern, is_regex, action, tag, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
+RETURNING ` + filterRuleColumns
+
+func (q *Queries) CreateFilterRule(ctx context.Context, arg database.CreateFilterRuleParams) (database.FilterRule, error) {
+	row := q.db.QueryRowContext(ctx, createFilterRule,
+		arg.ID,
+		arg.UserID,
+		arg.Field,
+		arg.Pattern,
+		arg.IsRegex,
+		arg.Action,
+		arg.Tag,
+		arg.CreatedAt,
+	)
+	return scanFilterRule(row)
+}
+
+const getFilterRulesForUser = `
+SELECT ` + filterRuleColumns + ` FROM filter_rules
+WHERE user_id = ?1
+ORDER BY created_at
+`
+
+func (q *Queries) GetFilterRulesForUser(ctx context.Context, userID uuid.UUID) ([]database.FilterRule, error) {
+	return q.queryFilterRules(ctx, getFilterRulesForUser, userID)
+}
+
+const getFilterRule = `
+SELECT ` + filterRuleColumns + ` FROM filter_rules
+WHERE id = ?1 AND user_id = ?2
+`
+
+func (q *Queries) GetFilterRule(ctx context.Context, arg database.GetFilterRuleParams) (database.FilterRule, error) {
+	return scanFilterRule(q.db.QueryRowContext(ctx, getFilterRule, arg.ID, arg.UserID))
+}
+
+const deleteFilterRule = `
+DELETE FROM filter_rules
+WHERE id = ?1 AND user_id = ?2
+`
+
+func (q *Queries) DeleteFilterRule(ctx context.Context, arg database.DeleteFilterRuleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFilterRule, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// The rules of every user following the feed.
+const getFilterRulesForFeed = `
+SELECT ` + filterRuleColumns + ` FROM filter_rules
+JOIN feed_follows ON feed_follows.user_id = filter_rules.user_id
+WHERE feed_follows.feed_id = ?1
+ORDER BY filter_rules.user_id, filter_rules.created_at
+`
+
+func (q *Queries) GetFilterRulesForFeed(ctx context.Context, feedID uuid.UUID) ([]database.FilterRule, error) {
+	return q.queryFilterRules(ctx, getFilterRulesForFeed, feedID)
+}
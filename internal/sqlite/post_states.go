@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Romasav/gator/internal/database"
+)
+
+const markPostRead = `
+INSERT INTO post_states (user_id, post_id, read_at)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET read_at = excluded.read_at
+`
+
+func (q *Queries) MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) error {
+	_, err := q.db.ExecContext(ctx, markPostRead, arg.UserID, arg.PostID, arg.ReadAt)
+	return err
+}
+
+const markPostStarred = `
+INSERT INTO post_states (user_id, post_id, starred_at)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET starred_at = excluded.starred_at
+`
+
+func (q *Queries) MarkPostStarred(ctx context.Context, arg database.MarkPostStarredParams) error {
+	_, err := q.db.ExecContext(ctx, markPostStarred, arg.UserID, arg.PostID, arg.StarredAt)
+	return err
+}
+
+const markPostMuted = `
+INSERT INTO post_states (user_id, post_id, muted_at)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET muted_at = excluded.muted_at
+`
+
+func (q *Queries) MarkPostMuted(ctx context.Context, arg database.MarkPostMutedParams) error {
+	_, err := q.db.ExecContext(ctx, markPostMuted, arg.UserID, arg.PostID, arg.MutedAt)
+	return err
+}
+
+const isPostMuted = `
+SELECT EXISTS (
+    SELECT 1 FROM post_states
+    WHERE user_id = ?1 AND post_id = ?2 AND muted_at IS NOT NULL
+)
+`
+
+func (q *Queries) IsPostMuted(ctx context.Context, arg database.IsPostMutedParams) (bool, error) {
+	var exists bool
+	err := q.db.QueryRowContext(ctx, isPostMuted, arg.UserID, arg.PostID).Scan(&exists)
+	return exists, err
+}
+
+const markAllPostsRead = `
+INSERT INTO post_states (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, ?1
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = ?2
+AND (?3 IS NULL OR feeds.url = ?3)
+AND (?4 IS NULL OR feed_follows.folder = ?4)
+AND posts.created_at <= ?5
+ON CONFLICT (user_id, post_id) DO UPDATE SET read_at = COALESCE(post_states.read_at, excluded.read_at)
+`
+
+func (q *Queries) MarkAllPostsRead(ctx context.Context, arg database.MarkAllPostsReadParams) error {
+	_, err := q.db.ExecContext(ctx, markAllPostsRead, arg.ReadAt, arg.UserID, arg.FeedUrl, arg.Folder, arg.OlderThan)
+	return err
+}
+
+const markPostPlayed = `
+INSERT INTO post_states (user_id, post_id, played_at)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET played_at = excluded.played_at
+`
+
+func (q *Queries) MarkPostPlayed(ctx context.Context, arg database.MarkPostPlayedParams) error {
+	_, err := q.db.ExecContext(ctx, markPostPlayed, arg.UserID, arg.PostID, arg.PlayedAt)
+	return err
+}
+
+const markPostDownloaded = `
+INSERT INTO post_states (user_id, post_id, downloaded_at, download_path)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT (user_id, post_id) DO UPDATE SET downloaded_at = excluded.downloaded_at, download_path = excluded.download_path
+`
+
+func (q *Queries) MarkPostDownloaded(ctx context.Context, arg database.MarkPostDownloadedParams) error {
+	_, err := q.db.ExecContext(ctx, markPostDownloaded, arg.UserID, arg.PostID, arg.DownloadedAt, arg.DownloadPath)
+	return err
+}
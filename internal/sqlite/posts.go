@@ -0,0 +1,453 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const postColumns = `posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.article_html, posts.article_text, posts.article_fetched_at, posts.content, posts.author, posts.categories`
+
+func scanPost(row scanner) (database.Post, error) {
+	var i database.Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.ArticleHtml,
+		&i.ArticleText,
+		&i.ArticleFetchedAt,
+		&i.Content,
+		&i.Author,
+		&i.Categories,
+	)
+	return i, err
+}
+
+func (q *Queries) queryPosts(ctx context.Context, query string, args ...interface{}) ([]database.Post, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.Post
+	for rows.Next() {
+		i, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createPost = `
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content, author, categories)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11)
+ON CONFLICT (url) DO NOTHING
+RETURNING ` + postColumns
+
+func (q *Queries) CreatePost(ctx context.Context, arg database.CreatePostParams) (database.Post, error) {
+	row := q.db.QueryRowContext(ctx, createPost,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.FeedID,
+		arg.Content,
+		arg.Author,
+		arg.Categories,
+	)
+	return scanPost(row)
+}
+
+const getPostByURL = `
+SELECT ` + postColumns + ` FROM posts WHERE url = ?1
+`
+
+func (q *Queries) GetPostByURL(ctx context.Context, url string) (database.Post, error) {
+	return scanPost(q.db.QueryRowContext(ctx, getPostByURL, url))
+}
+
+const getPostById = `
+SELECT ` + postColumns + ` FROM posts WHERE id = ?1
+`
+
+func (q *Queries) GetPostById(ctx context.Context, id uuid.UUID) (database.Post, error) {
+	return scanPost(q.db.QueryRowContext(ctx, getPostById, id))
+}
+
+// postForUserColumns matches the column list of the GetPostsForUser family
+// of queries, which all scan into the same row shape.
+const postForUserColumns = postColumns + `,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    post_states.read_at,
+    post_states.starred_at`
+
+// postTagsColumn lists the tags a post has for the user bound to ?1, which
+// every query of the family uses for the user id.
+const postTagsColumn = `,
+    COALESCE((
+        SELECT group_concat(tags.name, ',' ORDER BY tags.name)
+        FROM post_tags
+        JOIN tags ON tags.id = post_tags.tag_id
+        WHERE post_tags.post_id = posts.id AND tags.user_id = ?1
+    ), '') AS tags`
+
+func scanPostForUser(row scanner) (database.GetPostsForUserRow, error) {
+	var i database.GetPostsForUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.ArticleHtml,
+		&i.ArticleText,
+		&i.ArticleFetchedAt,
+		&i.Content,
+		&i.Author,
+		&i.Categories,
+		&i.FeedName,
+		&i.FeedUrl,
+		&i.ReadAt,
+		&i.StarredAt,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const createPostEnclosure = `
+INSERT INTO post_enclosures (id, post_id, url, media_type, length, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+ON CONFLICT (post_id, url) DO NOTHING
+`
+
+func (q *Queries) CreatePostEnclosure(ctx context.Context, arg database.CreatePostEnclosureParams) error {
+	_, err := q.db.ExecContext(ctx, createPostEnclosure,
+		arg.ID,
+		arg.PostID,
+		arg.Url,
+		arg.MediaType,
+		arg.Length,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const getPostEnclosures = `
+SELECT id, post_id, url, media_type, length, created_at FROM post_enclosures
+WHERE post_id = ?1
+ORDER BY created_at
+`
+
+func (q *Queries) GetPostEnclosures(ctx context.Context, postID uuid.UUID) ([]database.PostEnclosure, error) {
+	rows, err := q.db.QueryContext(ctx, getPostEnclosures, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.PostEnclosure
+	for rows.Next() {
+		var i database.PostEnclosure
+		if err := rows.Scan(
+			&i.ID,
+			&i.PostID,
+			&i.Url,
+			&i.MediaType,
+			&i.Length,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPostArticle = `
+UPDATE posts
+SET article_html = ?2, article_text = ?3, article_fetched_at = ?4
+WHERE id = ?1
+`
+
+func (q *Queries) SetPostArticle(ctx context.Context, arg database.SetPostArticleParams) error {
+	_, err := q.db.ExecContext(ctx, setPostArticle,
+		arg.ID,
+		arg.ArticleHtml,
+		arg.ArticleText,
+		arg.ArticleFetchedAt,
+	)
+	return err
+}
+
+const getPostsForUser = `
+SELECT ` + postForUserColumns + postTagsColumn + `
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = ?1
+AND (NOT ?2 OR post_states.read_at IS NULL)
+AND (NOT ?3 OR post_states.starred_at IS NOT NULL)
+AND (?4 IS NULL OR feeds.url = ?4)
+AND (?5 IS NULL OR feed_follows.folder = ?5)
+AND (post_states.muted_at IS NOT NULL) = ?6
+AND (?9 IS NULL OR EXISTS (
+    SELECT 1 FROM post_tags
+    JOIN tags ON tags.id = post_tags.tag_id
+    WHERE post_tags.post_id = posts.id
+    AND tags.user_id = feed_follows.user_id
+    AND tags.name = ?9
+))
+AND (?10 IS NULL
+    OR posts.title LIKE '%' || ?10 || '%' ESCAPE '\'
+    OR posts.description LIKE '%' || ?10 || '%' ESCAPE '\'
+    OR posts.content LIKE '%' || ?10 || '%' ESCAPE '\'
+    OR posts.article_text LIKE '%' || ?10 || '%' ESCAPE '\')
+ORDER BY posts.published_at DESC
+LIMIT ?8
+OFFSET ?7
+`
+
+func (q *Queries) GetPostsForUser(ctx context.Context, arg database.GetPostsForUserParams) ([]database.GetPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.StarredOnly,
+		arg.FeedUrl,
+		arg.Folder,
+		arg.MutedOnly,
+		arg.Offset,
+		arg.Limit,
+		arg.Tag,
+		arg.Query,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.GetPostsForUserRow
+	for rows.Next() {
+		i, err := scanPostForUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostForUserByIDRange = `
+SELECT ` + postForUserColumns + postTagsColumn + `
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = ?1
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = ?1
+WHERE posts.id BETWEEN ?2 AND ?3
+ORDER BY posts.id
+LIMIT 1
+`
+
+func (q *Queries) GetPostForUserByIDRange(ctx context.Context, arg database.GetPostForUserByIDRangeParams) (database.GetPostForUserByIDRangeRow, error) {
+	i, err := scanPostForUser(q.db.QueryRowContext(ctx, getPostForUserByIDRange, arg.UserID, arg.MinID, arg.MaxID))
+	return database.GetPostForUserByIDRangeRow(i), err
+}
+
+const getUnreadCountsForUser = `
+SELECT
+    feeds.id AS feed_id,
+    feeds.url AS feed_url,
+    feed_follows.folder,
+    COUNT(posts.id) AS unread_count,
+    MAX(posts.created_at) AS newest_created_at
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+JOIN posts ON posts.feed_id = feeds.id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = ?1
+AND post_states.read_at IS NULL
+AND post_states.muted_at IS NULL
+GROUP BY feeds.id, feeds.url, feed_follows.folder
+`
+
+func (q *Queries) GetUnreadCountsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetUnreadCountsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadCountsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.GetUnreadCountsForUserRow
+	for rows.Next() {
+		var i database.GetUnreadCountsForUserRow
+		var newest string
+		if err := rows.Scan(
+			&i.FeedID,
+			&i.FeedUrl,
+			&i.Folder,
+			&i.UnreadCount,
+			&newest,
+		); err != nil {
+			return nil, err
+		}
+		// Aggregates lose the column type, so the driver hands back text.
+		if i.NewestCreatedAt, err = parseTime(newest); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForDigest = `
+SELECT
+    ` + postColumns + `,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = ?1
+AND (posts.created_at > ?2 OR (posts.created_at = ?2 AND posts.id > ?3))
+AND posts.created_at <= ?4
+AND post_states.read_at IS NULL
+AND post_states.muted_at IS NULL
+ORDER BY posts.created_at, posts.id
+LIMIT ?5
+`
+
+func (q *Queries) GetPostsForDigest(ctx context.Context, arg database.GetPostsForDigestParams) ([]database.GetPostsForDigestRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForDigest,
+		arg.UserID,
+		arg.Since,
+		arg.SinceID,
+		arg.Until,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.GetPostsForDigestRow
+	for rows.Next() {
+		var i database.GetPostsForDigestRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.ArticleHtml,
+			&i.ArticleText,
+			&i.ArticleFetchedAt,
+			&i.Content,
+			&i.Author,
+			&i.Categories,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowedPosts = `
+SELECT
+    ` + postColumns + `,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = ?1
+ORDER BY posts.created_at DESC
+`
+
+func (q *Queries) GetFollowedPosts(ctx context.Context, userID uuid.UUID) ([]database.GetFollowedPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowedPosts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.GetFollowedPostsRow
+	for rows.Next() {
+		var i database.GetFollowedPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.ArticleHtml,
+			&i.ArticleText,
+			&i.ArticleFetchedAt,
+			&i.Content,
+			&i.Author,
+			&i.Categories,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Romasav/gator/internal/database"
+)
+
+const createSession = `
+INSERT INTO sessions (token_hash, user_id, created_at, expires_at)
+VALUES (?1, ?2, ?3, ?4)
+RETURNING token_hash, user_id, created_at, expires_at
+`
+
+func (q *Queries) CreateSession(ctx context.Context, arg database.CreateSessionParams) (database.Session, error) {
+	row := q.db.QueryRowContext(ctx, createSession,
+		arg.TokenHash,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	var i database.Session
+	err := row.Scan(
+		&i.TokenHash,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const deleteSession = `
+DELETE FROM sessions WHERE token_hash = ?1
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, tokenHash string) error {
+	_, err := q.db.ExecContext(ctx, deleteSession, tokenHash)
+	return err
+}
+
+const getUserBySession = `
+SELECT ` + userColumns + `
+FROM sessions
+JOIN users ON users.id = sessions.user_id
+WHERE sessions.token_hash = ?1
+AND sessions.expires_at > ?2
+`
+
+func (q *Queries) GetUserBySession(ctx context.Context, arg database.GetUserBySessionParams) (database.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, getUserBySession, arg.TokenHash, arg.Now))
+}
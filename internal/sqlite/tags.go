@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const tagColumns = `tags.id, tags.user_id, tags.name, tags.created_at`
+
+func scanTag(row scanner) (database.Tag, error) {
+	var i database.Tag
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+// Returns the user's tag with this name, creating it if needed.
+const createTag = `
+INSERT INTO tags (id, user_id, name, created_at)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT (user_id, name) DO UPDATE SET name = excluded.name
+RETURNING ` + tagColumns
+
+func (q *Queries) CreateTag(ctx context.Context, arg database.CreateTagParams) (database.Tag, error) {
+	row := q.db.QueryRowContext(ctx, createTag,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.CreatedAt,
+	)
+	return scanTag(row)
+}
+
+const tagPost = `
+INSERT INTO post_tags (post_id, tag_id, created_at)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (post_id, tag_id) DO NOTHING
+`
+
+func (q *Queries) TagPost(ctx context.Context, arg database.TagPostParams) error {
+	_, err := q.db.ExecContext(ctx, tagPost, arg.PostID, arg.TagID, arg.CreatedAt)
+	return err
+}
+
+const getTagByName = `
+SELECT ` + tagColumns + ` FROM tags
+WHERE user_id = ?1 AND name = ?2
+`
+
+func (q *Queries) GetTagByName(ctx context.Context, arg database.GetTagByNameParams) (database.Tag, error) {
+	return scanTag(q.db.QueryRowContext(ctx, getTagByName, arg.UserID, arg.Name))
+}
+
+const untagPost = `
+DELETE FROM post_tags
+WHERE post_id = ?1 AND tag_id = ?2
+`
+
+func (q *Queries) UntagPost(ctx context.Context, arg database.UntagPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, untagPost, arg.PostID, arg.TagID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getTagsForUser = `
+SELECT ` + tagColumns + `, COUNT(post_tags.post_id) AS post_count
+FROM tags
+LEFT JOIN post_tags ON post_tags.tag_id = tags.id
+WHERE tags.user_id = ?1
+GROUP BY tags.id
+ORDER BY tags.name
+`
+
+func (q *Queries) GetTagsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetTagsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTagsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.GetTagsForUserRow
+	for rows.Next() {
+		var i database.GetTagsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.PostCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteTag = `
+DELETE FROM tags
+WHERE user_id = ?1 AND name = ?2
+`
+
+func (q *Queries) DeleteTag(ctx context.Context, arg database.DeleteTagParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTag, arg.UserID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,208 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const userColumns = `users.id, users.created_at, users.updated_at, users.name, users.timezone, users.hashed_password, users.is_admin, users.email, users.last_digest_at, users.last_digest_post_id`
+
+func scanUser(row scanner) (database.User, error) {
+	var i database.User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Timezone,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Email,
+		&i.LastDigestAt,
+		&i.LastDigestPostID,
+	)
+	return i, err
+}
+
+func (q *Queries) queryUsers(ctx context.Context, query string, args ...interface{}) ([]database.User, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.User
+	for rows.Next() {
+		i, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// The first user to register becomes the admin.
+const createUser = `
+INSERT INTO users (id, created_at, updated_at, name, hashed_password, is_admin)
+VALUES (?1, ?2, ?3, ?4, ?5, NOT EXISTS (SELECT 1 FROM users))
+RETURNING ` + userColumns
+
+func (q *Queries) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	row := q.db.QueryRowContext(ctx, createUser,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.HashedPassword,
+	)
+	return scanUser(row)
+}
+
+const deleteAllUsers = `
+DELETE FROM users
+`
+
+func (q *Queries) DeleteAllUsers(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteAllUsers)
+	return err
+}
+
+const getUser = `
+SELECT ` + userColumns + ` FROM users WHERE name = ?1
+`
+
+func (q *Queries) GetUser(ctx context.Context, name string) (database.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, getUser, name))
+}
+
+const getUserById = `
+SELECT ` + userColumns + ` FROM users WHERE id = ?1
+`
+
+func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (database.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, getUserById, id))
+}
+
+const getUsers = `
+SELECT ` + userColumns + ` FROM users
+`
+
+func (q *Queries) GetUsers(ctx context.Context) ([]database.User, error) {
+	return q.queryUsers(ctx, getUsers)
+}
+
+const updateUserTimezone = `
+UPDATE users
+SET timezone = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING ` + userColumns
+
+func (q *Queries) UpdateUserTimezone(ctx context.Context, arg database.UpdateUserTimezoneParams) (database.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, updateUserTimezone, arg.ID, arg.Timezone, arg.UpdatedAt))
+}
+
+const updateUserPassword = `
+UPDATE users
+SET hashed_password = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING ` + userColumns
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg database.UpdateUserPasswordParams) (database.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, updateUserPassword, arg.ID, arg.HashedPassword, arg.UpdatedAt))
+}
+
+const setUserAdmin = `
+UPDATE users
+SET is_admin = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING ` + userColumns
+
+func (q *Queries) SetUserAdmin(ctx context.Context, arg database.SetUserAdminParams) (database.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, setUserAdmin, arg.ID, arg.IsAdmin, arg.UpdatedAt))
+}
+
+const countOtherAdmins = `
+SELECT COUNT(*) FROM users WHERE is_admin AND id <> ?1`
+
+func (q *Queries) CountOtherAdmins(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+	err := q.db.QueryRowContext(ctx, countOtherAdmins, id).Scan(&count)
+	return count, err
+}
+
+const renameUser = `
+UPDATE users
+SET name = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING ` + userColumns
+
+func (q *Queries) RenameUser(ctx context.Context, arg database.RenameUserParams) (database.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, renameUser, arg.ID, arg.Name, arg.UpdatedAt))
+}
+
+const updateUserEmail = `
+UPDATE users
+SET email = ?2, updated_at = ?3
+WHERE id = ?1
+RETURNING ` + userColumns
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg database.UpdateUserEmailParams) (database.User, error) {
+	return scanUser(q.db.QueryRowContext(ctx, updateUserEmail, arg.ID, arg.Email, arg.UpdatedAt))
+}
+
+const getUsersWithEmail = `
+SELECT ` + userColumns + ` FROM users
+WHERE email IS NOT NULL
+ORDER BY name
+`
+
+func (q *Queries) GetUsersWithEmail(ctx context.Context) ([]database.User, error) {
+	return q.queryUsers(ctx, getUsersWithEmail)
+}
+
+const setUserLastDigest = `
+UPDATE users
+SET last_digest_at = ?2, last_digest_post_id = ?3
+WHERE id = ?1
+`
+
+func (q *Queries) SetUserLastDigest(ctx context.Context, arg database.SetUserLastDigestParams) error {
+	_, err := q.db.ExecContext(ctx, setUserLastDigest, arg.ID, arg.LastDigestAt, arg.LastDigestPostID)
+	return err
+}
+
+const deleteUser = `
+DELETE FROM users WHERE id = ?1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
+const getUserStats = `
+SELECT
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = ?1) AS follow_count,
+    (SELECT COUNT(*)
+     FROM posts
+     JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+     LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+     WHERE feed_follows.user_id = ?1
+     AND post_states.read_at IS NULL
+     AND post_states.muted_at IS NULL) AS unread_count
+`
+
+func (q *Queries) GetUserStats(ctx context.Context, userID uuid.UUID) (database.GetUserStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserStats, userID)
+	var i database.GetUserStatsRow
+	err := row.Scan(&i.FollowCount, &i.UnreadCount)
+	return i, err
+}
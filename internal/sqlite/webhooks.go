@@ -0,0 +1,217 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const webhookColumns = `webhooks.id, webhooks.user_id, webhooks.url, webhooks.secret, webhooks.feed_id, webhooks.keyword, webhooks.created_at`
+
+func scanWebhook(row scanner) (database.Webhook, error) {
+	var i database.Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Url,
+		&i.Secret,
+		&i.FeedID,
+		&i.Keyword,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createWebhook = `
+INSERT INTO webhooks (id, user_id, url, secret, feed_id, keyword, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+RETURNING ` + webhookColumns
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg database.CreateWebhookParams) (database.Webhook, error) {
+	row := q.db.QueryRowContext(ctx, createWebhook,
+		arg.ID,
+		arg.UserID,
+		arg.Url,
+		arg.Secret,
+		arg.FeedID,
+		arg.Keyword,
+		arg.CreatedAt,
+	)
+	return scanWebhook(row)
+}
+
+const getWebhooksForUser = `
+SELECT ` + webhookColumns + `, feeds.url AS feed_url
+FROM webhooks
+LEFT JOIN feeds ON feeds.id = webhooks.feed_id
+WHERE webhooks.user_id = ?1
+ORDER BY webhooks.created_at
+`
+
+func (q *Queries) GetWebhooksForUser(ctx context.Context, userID uuid.UUID) ([]database.GetWebhooksForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhooksForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.GetWebhooksForUserRow
+	for rows.Next() {
+		var i database.GetWebhooksForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Url,
+			&i.Secret,
+			&i.FeedID,
+			&i.Keyword,
+			&i.CreatedAt,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhook = `
+SELECT ` + webhookColumns + ` FROM webhooks
+WHERE id = ?1 AND user_id = ?2
+`
+
+func (q *Queries) GetWebhook(ctx context.Context, arg database.GetWebhookParams) (database.Webhook, error) {
+	return scanWebhook(q.db.QueryRowContext(ctx, getWebhook, arg.ID, arg.UserID))
+}
+
+const deleteWebhook = `
+DELETE FROM webhooks
+WHERE id = ?1 AND user_id = ?2
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, arg database.DeleteWebhookParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhook, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getWebhooksForFeed = `
+SELECT ` + webhookColumns + ` FROM webhooks
+WHERE (webhooks.feed_id IS NULL OR webhooks.feed_id = ?1)
+AND EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.user_id = webhooks.user_id
+    AND feed_follows.feed_id = ?1
+)
+`
+
+func (q *Queries) GetWebhooksForFeed(ctx context.Context, feedID uuid.UUID) ([]database.Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhooksForFeed, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.Webhook
+	for rows.Next() {
+		i, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWebhookDelivery = `
+INSERT INTO webhook_deliveries (id, webhook_id, post_id, attempt, status_code, error, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+`
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, createWebhookDelivery,
+		arg.ID,
+		arg.WebhookID,
+		arg.PostID,
+		arg.Attempt,
+		arg.StatusCode,
+		arg.Error,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const getWebhookDeliveries = `
+SELECT
+    webhook_deliveries.id, webhook_deliveries.webhook_id, webhook_deliveries.post_id, webhook_deliveries.attempt,
+    webhook_deliveries.status_code, webhook_deliveries.error, webhook_deliveries.created_at,
+    webhooks.url AS webhook_url,
+    posts.title AS post_title
+FROM webhook_deliveries
+JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+LEFT JOIN posts ON posts.id = webhook_deliveries.post_id
+WHERE webhooks.user_id = ?1
+AND (?2 IS NULL OR webhooks.id = ?2)
+ORDER BY webhook_deliveries.created_at DESC
+LIMIT ?3
+`
+
+func (q *Queries) GetWebhookDeliveries(ctx context.Context, arg database.GetWebhookDeliveriesParams) ([]database.GetWebhookDeliveriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookDeliveries, arg.UserID, arg.WebhookID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []database.GetWebhookDeliveriesRow
+	for rows.Next() {
+		var i database.GetWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.PostID,
+			&i.Attempt,
+			&i.StatusCode,
+			&i.Error,
+			&i.CreatedAt,
+			&i.WebhookUrl,
+			&i.PostTitle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteWebhookDeliveriesBefore = `
+DELETE FROM webhook_deliveries
+WHERE created_at < ?1
+`
+
+func (q *Queries) DeleteWebhookDeliveriesBefore(ctx context.Context, createdAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhookDeliveriesBefore, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
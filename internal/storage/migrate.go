@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func (s *Storage) MigrationProvider() (*goose.Provider, error) {
+	provider, err := goose.NewProvider(s.Dialect, s.DB, s.Migrations, goose.WithGoMigrations(goMigrations()...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return provider, nil
+}
+
+// CheckSchemaVersion returns an error unless the database is migrated to
+// exactly the version embedded in this build.
+func (s *Storage) CheckSchemaVersion(ctx context.Context) error {
+	provider, err := s.MigrationProvider()
+	if err != nil {
+		return err
+	}
+
+	current, target, err := provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get schema version: %w", err)
+	}
+	if current < target {
+		return fmt.Errorf("database schema is at version %v but gator needs version %v, run \"gator migrate up\" first", current, target)
+	}
+	if current > target {
+		return fmt.Errorf("database schema is at version %v which is newer than this gator build supports (%v), please upgrade gator", current, target)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Romasav/gator/rssFeed"
+	"github.com/google/uuid"
+	"github.com/pressly/goose/v3"
+)
+
+// goMigrations are the migrations that need Go code. They run on both
+// backends, so their SQL sticks to what PostgreSQL and SQLite have in
+// common. Their versions are taken, the SQL migrations must skip them.
+func goMigrations() []*goose.Migration {
+	return []*goose.Migration{
+		// Nothing to undo, the original URLs are gone.
+		goose.NewGoMigration(22, &goose.GoFunc{RunTx: normalizePostURLs}, &goose.GoFunc{}),
+	}
+}
+
+// normalizePostURLs rewrites the URLs of the posts stored before links were
+// normalized, so they match the posts fetched since. Posts that turn out
+// to be the same are merged into the oldest one, which takes over the read
+// state and tags of the others.
+func normalizePostURLs(ctx context.Context, tx *sql.Tx) error {
+	type post struct {
+		id  uuid.UUID
+		url string
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT id, url FROM posts ORDER BY created_at, id`)
+	if err != nil {
+		return fmt.Errorf("failed to get posts: %w", err)
+	}
+	var posts []post
+	for rows.Next() {
+		var p post
+		if err := rows.Scan(&p.id, &p.url); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read post: %w", err)
+		}
+		posts = append(posts, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read posts: %w", err)
+	}
+
+	kept := make(map[string]uuid.UUID, len(posts))
+	var renamed []post
+	for _, p := range posts {
+		normalized := rssFeed.NormalizeURL(p.url)
+		if keptID, ok := kept[normalized]; ok {
+			err := mergePost(ctx, tx, keptID, p.id)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		kept[normalized] = p.id
+		if normalized != p.url {
+			renamed = append(renamed, post{id: p.id, url: normalized})
+		}
+	}
+
+	// Only now that the duplicates are gone can the URLs be unique again.
+	for _, p := range renamed {
+		_, err := tx.ExecContext(ctx, `UPDATE posts SET url = $1 WHERE id = $2`, p.url, p.id)
+		if err != nil {
+			return fmt.Errorf("failed to update post %s: %w", p.id, err)
+		}
+	}
+	return nil
+}
+
+// mergePost adds the per-user state and tags of the duplicate post to the
+// kept one, without overwriting what the kept one has, and deletes the
+// duplicate.
+func mergePost(ctx context.Context, tx *sql.Tx, keptID, duplicateID uuid.UUID) error {
+	// The kept id is read from posts rather than passed as a parameter in
+	// the select list, where PostgreSQL would take it for text.
+	_, err := tx.ExecContext(ctx, `
+INSERT INTO post_states (user_id, post_id, read_at, starred_at, muted_at, played_at, downloaded_at, download_path)
+SELECT post_states.user_id, kept.id, post_states.read_at, post_states.starred_at, post_states.muted_at,
+    post_states.played_at, post_states.downloaded_at, post_states.download_path
+FROM post_states, posts AS kept
+WHERE post_states.post_id = $2 AND kept.id = $1
+ON CONFLICT (user_id, post_id) DO UPDATE SET
+    read_at = COALESCE(post_states.read_at, excluded.read_at),
+    starred_at = COALESCE(post_states.starred_at, excluded.starred_at),
+    muted_at = COALESCE(post_states.muted_at, excluded.muted_at),
+    played_at = COALESCE(post_states.played_at, excluded.played_at),
+    downloaded_at = COALESCE(post_states.downloaded_at, excluded.downloaded_at),
+    download_path = COALESCE(post_states.download_path, excluded.download_path)`, keptID, duplicateID)
+	if err != nil {
+		return fmt.Errorf("failed to merge post states: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO post_tags (post_id, tag_id, created_at)
+SELECT kept.id, post_tags.tag_id, post_tags.created_at
+FROM post_tags, posts AS kept
+WHERE post_tags.post_id = $2 AND kept.id = $1
+ON CONFLICT (post_id, tag_id) DO NOTHING`, keptID, duplicateID)
+	if err != nil {
+		return fmt.Errorf("failed to merge post tags: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM posts WHERE id = $1`, duplicateID)
+	if err != nil {
+		return fmt.Errorf("failed to delete duplicate post %s: %w", duplicateID, err)
+	}
+	return nil
+}
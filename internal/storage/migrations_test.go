@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizePostURLsSQLite(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	provider, err := s.MigrationProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.UpTo(ctx, 21); err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		userID    = "00000000-0000-0000-0000-000000000001"
+		feedID    = "00000000-0000-0000-0000-000000000002"
+		tagID     = "00000000-0000-0000-0000-000000000003"
+		keptID    = "aaaaaaaa-0000-0000-0000-000000000001"
+		dupID     = "bbbbbbbb-0000-0000-0000-000000000001"
+		renamedID = "cccccccc-0000-0000-0000-000000000001"
+	)
+	for _, stmt := range []string{
+		`INSERT INTO users (id, created_at, updated_at, name) VALUES ('` + userID + `', datetime(), datetime(), 'alice')`,
+		`INSERT INTO feeds (id, created_at, updated_at, name, url, user_id) VALUES ('` + feedID + `', datetime(), datetime(), 'feed', 'https://example.com/feed', '` + userID + `')`,
+		`INSERT INTO tags (id, user_id, name, created_at) VALUES ('` + tagID + `', '` + userID + `', 'later', datetime())`,
+		`INSERT INTO posts (id, created_at, updated_at, title, url, feed_id) VALUES ('` + keptID + `', '2024-01-01 00:00:00', datetime(), 'a', 'https://example.com/a', '` + feedID + `')`,
+		`INSERT INTO posts (id, created_at, updated_at, title, url, feed_id) VALUES ('` + dupID + `', '2024-01-02 00:00:00', datetime(), 'a', 'https://Example.com/a?utm_source=x', '` + feedID + `')`,
+		`INSERT INTO posts (id, created_at, updated_at, title, url, feed_id) VALUES ('` + renamedID + `', '2024-01-03 00:00:00', datetime(), 'b', 'https://example.com/b#top', '` + feedID + `')`,
+		`INSERT INTO post_states (user_id, post_id, read_at) VALUES ('` + userID + `', '` + keptID + `', datetime())`,
+		`INSERT INTO post_states (user_id, post_id, starred_at) VALUES ('` + userID + `', '` + dupID + `', datetime())`,
+		`INSERT INTO post_tags (post_id, tag_id, created_at) VALUES ('` + dupID + `', '` + tagID + `', datetime())`,
+	} {
+		if _, err := s.DB.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if _, err := provider.UpTo(ctx, 22); err != nil {
+		t.Fatal(err)
+	}
+
+	urls := map[string]string{}
+	rows, err := s.DB.Query(`SELECT id, url FROM posts`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var id, url string
+		if err := rows.Scan(&id, &url); err != nil {
+			t.Fatal(err)
+		}
+		urls[id] = url
+	}
+	rows.Close()
+	want := map[string]string{keptID: "https://example.com/a", renamedID: "https://example.com/b"}
+	if len(urls) != len(want) || urls[keptID] != want[keptID] || urls[renamedID] != want[renamedID] {
+		t.Errorf("posts = %v, want %v", urls, want)
+	}
+
+	var read, starred bool
+	err = s.DB.QueryRow(`SELECT read_at IS NOT NULL, starred_at IS NOT NULL FROM post_states WHERE post_id = ?`, keptID).Scan(&read, &starred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !read || !starred {
+		t.Errorf("merged state read=%v starred=%v, want both", read, starred)
+	}
+
+	var tagged int
+	err = s.DB.QueryRow(`SELECT COUNT(*) FROM post_tags WHERE post_id = ? AND tag_id = ?`, keptID, tagID).Scan(&tagged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tagged != 1 {
+		t.Errorf("kept post has %d tags, want 1", tagged)
+	}
+}
@@ -0,0 +1,112 @@
+// Package storage opens the database named by the config's db_url and
+// hands out the matching database.Querier implementation.
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strings"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/internal/sqlite"
+	postgresschema "github.com/Romasav/gator/sql/schema"
+	sqliteschema "github.com/Romasav/gator/sql/sqlite/schema"
+	"github.com/lib/pq"
+	"github.com/pressly/goose/v3"
+	moderncsqlite "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+type Storage struct {
+	DB         *sql.DB
+	Dialect    goose.Dialect
+	Migrations fs.FS
+	newQueries func(database.DBTX) database.Querier
+}
+
+// Open picks the backend from the scheme of dbURL: postgres:// and
+// postgresql:// use PostgreSQL, sqlite:// and sqlite: use a SQLite file.
+func Open(dbURL string) (*Storage, error) {
+	parsed, err := url.Parse(dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse db url: %w", err)
+	}
+
+	switch parsed.Scheme {
+	case "postgres", "postgresql":
+		db, err := sql.Open("postgres", dbURL)
+		if err != nil {
+			return nil, fmt.Errorf("could not open postgres database: %w", err)
+		}
+		return &Storage{
+			DB:         db,
+			Dialect:    goose.DialectPostgres,
+			Migrations: postgresschema.FS,
+			newQueries: func(db database.DBTX) database.Querier { return database.New(db) },
+		}, nil
+	case "sqlite", "sqlite3":
+		db, err := sql.Open("sqlite", sqliteDSN(dbURL))
+		if err != nil {
+			return nil, fmt.Errorf("could not open sqlite database: %w", err)
+		}
+		// SQLite allows a single writer, and the pragmas below are set per
+		// connection, so keep everything on one connection.
+		db.SetMaxOpenConns(1)
+		return &Storage{
+			DB:         db,
+			Dialect:    goose.DialectSQLite3,
+			Migrations: sqliteschema.FS,
+			newQueries: func(db database.DBTX) database.Querier { return sqlite.New(db) },
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported db url scheme %q, expected postgres or sqlite", parsed.Scheme)
+	}
+}
+
+// sqliteDSN turns sqlite:///abs/path.db, sqlite://rel/path.db or
+// sqlite:path.db into a modernc.org/sqlite data source name.
+func sqliteDSN(dbURL string) string {
+	path := dbURL
+	for _, prefix := range []string{"sqlite3://", "sqlite://", "sqlite3:", "sqlite:"} {
+		if strings.HasPrefix(path, prefix) {
+			path = strings.TrimPrefix(path, prefix)
+			break
+		}
+	}
+
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Set("_time_format", "sqlite")
+	return "file:" + path + "?" + params.Encode()
+}
+
+func (s *Storage) Queries() database.Querier {
+	return s.newQueries(s.DB)
+}
+
+// WithTx returns a Querier whose statements run inside tx.
+func (s *Storage) WithTx(tx *sql.Tx) database.Querier {
+	return s.newQueries(tx)
+}
+
+func (s *Storage) Close() error {
+	return s.DB.Close()
+}
+
+// IsUniqueViolation reports whether err was caused by a unique constraint,
+// with either backend.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	var sqliteErr *moderncsqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
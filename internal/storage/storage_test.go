@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsUniqueViolationSQLite(t *testing.T) {
+	s, err := Open("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	_, err = s.DB.Exec(`CREATE TABLE feeds (url TEXT NOT NULL UNIQUE)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.DB.Exec(`INSERT INTO feeds (url) VALUES ('https://example.com/feed')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.DB.Exec(`INSERT INTO feeds (url) VALUES ('https://example.com/feed')`)
+	if !IsUniqueViolation(err) {
+		t.Errorf("IsUniqueViolation(%v) = false, want true", err)
+	}
+	_, err = s.DB.Exec(`INSERT INTO feeds (url) VALUES (NULL)`)
+	if err == nil || IsUniqueViolation(err) {
+		t.Errorf("IsUniqueViolation(%v) = true, want false", err)
+	}
+	if IsUniqueViolation(errors.New("unique")) {
+		t.Error("IsUniqueViolation reported a plain error as a violation")
+	}
+}
+
+func TestSqliteDSN(t *testing.T) {
+	tests := map[string]string{
+		"sqlite:///var/lib/gator.db": "file:/var/lib/gator.db?",
+		"sqlite://gator.db":          "file:gator.db?",
+		"sqlite:gator.db":            "file:gator.db?",
+		"sqlite3://gator.db":         "file:gator.db?",
+	}
+	for dbURL, prefix := range tests {
+		got := sqliteDSN(dbURL)
+		if len(got) < len(prefix) || got[:len(prefix)] != prefix {
+			t.Errorf("sqliteDSN(%q) = %q, want prefix %q", dbURL, got, prefix)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	EventPostCreated = "post.created"
+	EventPing        = "ping"
+
+	// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of
+	// the request body, keyed with the webhook secret.
+	SignatureHeader = "X-Gator-Signature"
+	EventHeader     = "X-Gator-Event"
+	DeliveryHeader  = "X-Gator-Delivery"
+)
+
+// Payload is the JSON body posted to a webhook.
+type Payload struct {
+	Event string       `json:"event"`
+	Feed  *PayloadFeed `json:"feed,omitempty"`
+	Post  *PayloadPost `json:"post,omitempty"`
+}
+
+type PayloadFeed struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type PayloadPost struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Attempt describes a single try at delivering a webhook.
+type Attempt struct {
+	Number     int
+	StatusCode int
+	Err        error
+}
+
+// Sign returns the value of the signature header for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type Sender struct {
+	Client      *http.Client
+	MaxAttempts int
+	// Backoff is the wait before the second attempt, doubled for every
+	// attempt after that.
+	Backoff time.Duration
+}
+
+func NewSender() *Sender {
+	return &Sender{
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts: 4,
+		Backoff:     time.Second,
+	}
+}
+
+// Send posts body to url, retrying network errors and 429 or 5xx responses
+// with exponential backoff. record, if not nil, is called after every
+// attempt so the caller can keep a delivery log.
+func (s *Sender) Send(ctx context.Context, url, secret, event, deliveryID string, body []byte, record func(Attempt)) error {
+	backoff := s.Backoff
+	var err error
+	for number := 1; number <= s.MaxAttempts; number++ {
+		if number > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		var statusCode int
+		statusCode, err = s.post(ctx, url, secret, event, deliveryID, body)
+		if record != nil {
+			record(Attempt{Number: number, StatusCode: statusCode, Err: err})
+		}
+		if err == nil {
+			return nil
+		}
+		if statusCode != 0 && !retryable(statusCode) {
+			return err
+		}
+	}
+	return fmt.Errorf("giving up after %v attempts: %w", s.MaxAttempts, err)
+}
+
+func (s *Sender) post(ctx context.Context, url, secret, event, deliveryID string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gator")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(SignatureHeader, Sign(secret, body))
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status: %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
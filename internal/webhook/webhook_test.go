@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '{"event":"ping"}' | openssl dgst -sha256 -hmac secret
+	got := Sign("secret", []byte(`{"event":"ping"}`))
+	want := "sha256=4f4bb3a54e99c4a20e243485229f9b08c66e09104ba6f79c23ce647242a4ce84"
+	if got != want {
+		t.Errorf("Sign = %q, want %q", got, want)
+	}
+	if got == Sign("other", []byte(`{"event":"ping"}`)) {
+		t.Error("Sign does not depend on the secret")
+	}
+}
+
+func newTestSender() *Sender {
+	return &Sender{
+		Client:      &http.Client{Timeout: time.Second},
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	}
+}
+
+func TestSendSignsRequest(t *testing.T) {
+	body := []byte(`{"event":"post.created"}`)
+	var got *http.Request
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	err := newTestSender().Send(context.Background(), server.URL, "secret", EventPostCreated, "delivery-1", body, nil)
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if string(gotBody) != string(body) {
+		t.Errorf("body = %s, want %s", gotBody, body)
+	}
+	if sig := got.Header.Get(SignatureHeader); sig != Sign("secret", body) {
+		t.Errorf("%s = %q, want %q", SignatureHeader, sig, Sign("secret", body))
+	}
+	if event := got.Header.Get(EventHeader); event != EventPostCreated {
+		t.Errorf("%s = %q, want %q", EventHeader, event, EventPostCreated)
+	}
+	if id := got.Header.Get(DeliveryHeader); id != "delivery-1" {
+		t.Errorf("%s = %q, want %q", DeliveryHeader, id, "delivery-1")
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{"success", []int{200}, false, 1},
+		{"server error then success", []int{500, 503, 200}, false, 3},
+		{"rate limited then success", []int{429, 200}, false, 2},
+		{"client error is not retried", []int{400}, true, 1},
+		{"gives up", []int{500, 500, 500}, true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1))
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+			}))
+			defer server.Close()
+
+			var attempts []Attempt
+			err := newTestSender().Send(context.Background(), server.URL, "secret", EventPing, "id", []byte("{}"), func(a Attempt) {
+				attempts = append(attempts, a)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(attempts) != tt.wantAttempts || int(calls.Load()) != tt.wantAttempts {
+				t.Errorf("attempts = %d, requests = %d, want %d", len(attempts), calls.Load(), tt.wantAttempts)
+			}
+			for i, a := range attempts {
+				if a.Number != i+1 || a.StatusCode != tt.statuses[i] {
+					t.Errorf("attempt %d = %+v, want number %d and status %d", i, a, i+1, tt.statuses[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSendStopsOnCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sender := newTestSender()
+	sender.Backoff = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	err := sender.Send(ctx, server.URL, "secret", EventPing, "id", []byte("{}"), func(Attempt) { cancel() })
+	if err != context.Canceled {
+		t.Errorf("Send error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -1,15 +1,14 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"errors"
 	"log"
 	"os"
+	_ "time/tzdata"
 
 	"github.com/Romasav/gator/internal/config"
-	"github.com/Romasav/gator/internal/database"
-
-	_ "github.com/lib/pq"
+	"github.com/Romasav/gator/internal/storage"
 )
 
 func main() {
@@ -18,32 +17,71 @@ func main() {
 		log.Fatalf("could not get config: %v", err.Error())
 	}
 
-	db, err := sql.Open("postgres", con.DbUrl)
+	store, err := storage.Open(con.DbUrl)
 	if err != nil {
 		log.Fatalf("could not open a connection with db: %v", err.Error())
 	}
-	dbQueries := database.New(db)
+	defer store.Close()
 
-	state := newState(dbQueries, con)
+	state := newState(store, con)
 
 	commands := newCommands()
 	commands.register("login", handlerLogin)
+	commands.register("logout", handlerLogout)
 	commands.register("register", handlerRegister)
-	commands.register("reset", handlerReset)
+	commands.register("password", middlewareLoggedIn(handlerPassword))
+	commands.register("reset", middlewareAdmin(handlerReset))
+	commands.register("admin", middlewareAdmin(handlerAdmin))
 	commands.register("users", handlerUsers)
 	commands.register("agg", handlerAggregator)
 	commands.register("addfeed", middlewareLoggedIn(handlerCreateFeed))
+	commands.register("deletefeed", middlewareAdmin(handlerDeleteFeed))
+	commands.register("feed", middlewareAdmin(handlerFeed))
 	commands.register("feeds", handlerFeeds)
 	commands.register("follow", middlewareLoggedIn(handlerFollow))
 	commands.register("following", middlewareLoggedIn(handlerFollowing))
 	commands.register("unfollow", middlewareLoggedIn(handlerUnfollow))
+	commands.register("folder", middlewareLoggedIn(handlerFolder))
 	commands.register("browse", middlewareLoggedIn(handlerBrowse))
+	commands.register("search", middlewareLoggedIn(handlerSearch))
+	commands.register("tag", middlewareLoggedIn(handlerTag))
+	commands.register("untag", middlewareLoggedIn(handlerUntag))
+	commands.register("tags", middlewareLoggedIn(handlerTags))
+	commands.register("podcasts", middlewareLoggedIn(handlerPodcasts))
+	commands.register("episodes", middlewareLoggedIn(handlerEpisodes))
+	commands.register("download", middlewareLoggedIn(handlerDownload))
+	commands.register("played", middlewareLoggedIn(handlerPlayed))
+	commands.register("unplayed", middlewareLoggedIn(handlerUnplayed))
+	commands.register("feedout", middlewareLoggedIn(handlerFeedOut))
+	commands.register("tui", middlewareLoggedIn(handlerTUI))
+	commands.register("email", middlewareLoggedIn(handlerEmail))
+	commands.register("digest", handlerDigest)
+	commands.register("timezone", middlewareLoggedIn(handlerTimezone))
+	commands.register("rename", middlewareLoggedIn(handlerRename))
+	commands.register("deleteaccount", middlewareLoggedIn(handlerDeleteAccount))
+	commands.register("profile", middlewareLoggedIn(handlerProfile))
+	commands.register("markread", middlewareLoggedIn(handlerMarkRead))
+	commands.register("markunread", middlewareLoggedIn(handlerMarkUnread))
+	commands.register("star", middlewareLoggedIn(handlerStar))
+	commands.register("unstar", middlewareLoggedIn(handlerUnstar))
+	commands.register("token", middlewareLoggedIn(handlerToken))
+	commands.register("rule", middlewareLoggedIn(handlerRule))
+	commands.register("webhook", middlewareLoggedIn(handlerWebhook))
+	commands.register("serve", handlerServe)
+	commands.register("migrate", handlerMigrate)
 
 	command, err := parseArgs()
 	if err != nil {
 		log.Fatalf("could not get the command: %v", err.Error())
 	}
 
+	if command.Name != "migrate" {
+		err = store.CheckSchemaVersion(context.Background())
+		if err != nil {
+			log.Fatalf("could not use the database: %v", err.Error())
+		}
+	}
+
 	err = commands.run(state, command)
 	if err != nil {
 		log.Fatalf("could not run the command: %v", err.Error())
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/Romasav/gator/internal/database"
@@ -9,10 +8,19 @@ import (
 
 func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
 	return func(s *state, cmd command) error {
-		user, err := s.db.GetUser(context.Background(), s.config.Username)
+		user, err := currentUser(s)
 		if err != nil {
 			return fmt.Errorf("failed to get current user: %w", err)
 		}
 		return handler(s, cmd, user)
 	}
 }
+
+func middlewareAdmin(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
+	return middlewareLoggedIn(func(s *state, cmd command, user database.User) error {
+		if !user.IsAdmin {
+			return fmt.Errorf("the %v command is only available to admins", cmd.Name)
+		}
+		return handler(s, cmd, user)
+	})
+}
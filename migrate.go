@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func handlerMigrate(s *state, cmd command) error {
+	if len(cmd.Arguments) != 1 {
+		return fmt.Errorf("migrate requires exactly 1 argument (up, down or status), found %v arguments", len(cmd.Arguments))
+	}
+
+	provider, err := s.storage.MigrationProvider()
+	if err != nil {
+		return err
+	}
+
+	switch cmd.Arguments[0] {
+	case "up":
+		results, err := provider.Up(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+		if len(results) == 0 {
+			fmt.Println("The database is already up to date.")
+		}
+		for _, result := range results {
+			fmt.Printf("Applied %s (%v)\n", migrationName(result.Source), result.Duration)
+		}
+	case "down":
+		result, err := provider.Down(context.Background())
+		if errors.Is(err, goose.ErrNoNextVersion) {
+			fmt.Println("There are no migrations to roll back.")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to roll back migration: %w", err)
+		}
+		fmt.Printf("Rolled back %s (%v)\n", migrationName(result.Source), result.Duration)
+	case "status":
+		statuses, err := provider.Status(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+		for _, status := range statuses {
+			appliedAt := "Pending"
+			if status.State == goose.StateApplied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-20s %s\n", appliedAt, migrationName(status.Source))
+		}
+	default:
+		return fmt.Errorf("unknown migrate subcommand %v, expected up, down or status", cmd.Arguments[0])
+	}
+
+	return nil
+}
+
+// migrationName returns the file of a SQL migration, or the version of one
+// written in Go.
+func migrationName(source *goose.Source) string {
+	if source.Path != "" {
+		return source.Path
+	}
+	return fmt.Sprintf("%03d (built in)", source.Version)
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/internal/download"
+	"github.com/Romasav/gator/rssFeed"
+	"github.com/google/uuid"
+)
+
+// createEpisode stores the podcast details of an item that carries a media
+// enclosure. Items without one are not episodes and are skipped. Episodes
+// without artwork of their own get the podcast's.
+func createEpisode(s *state, post database.Post, item rssFeed.RSSItem, podcastImage string, warn func(format string, args ...interface{})) {
+	enclosure, ok := item.MediaEnclosure()
+	if !ok {
+		return
+	}
+
+	createEpisodeParams := database.CreateEpisodeParams{
+		PostID:    post.ID,
+		MediaUrl:  strings.TrimSpace(enclosure.URL),
+		MediaType: strings.TrimSpace(enclosure.Type),
+		CreatedAt: time.Now().UTC(),
+	}
+	if length, err := strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64); err == nil && length > 0 {
+		createEpisodeParams.MediaLength = sql.NullInt64{Int64: length, Valid: true}
+	}
+	if duration, ok := item.DurationSeconds(); ok {
+		createEpisodeParams.DurationSeconds = sql.NullInt32{Int32: duration, Valid: true}
+	}
+	if episode, ok := item.EpisodeNumber(); ok {
+		createEpisodeParams.EpisodeNumber = sql.NullInt32{Int32: episode, Valid: true}
+	}
+	if season, ok := item.SeasonNumber(); ok {
+		createEpisodeParams.SeasonNumber = sql.NullInt32{Int32: season, Valid: true}
+	}
+	if explicit, ok := item.IsExplicit(); ok {
+		createEpisodeParams.Explicit = sql.NullBool{Bool: explicit, Valid: true}
+	}
+	image := strings.TrimSpace(item.ITunesItem.Image.Href)
+	if image == "" {
+		image = strings.TrimSpace(podcastImage)
+	}
+	createEpisodeParams.ImageUrl = sql.NullString{String: image, Valid: image != ""}
+
+	err := s.db.CreateEpisode(context.Background(), createEpisodeParams)
+	if err != nil {
+		warn("Error saving episode: %v", err)
+	}
+}
+
+func handlerPodcasts(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) != 0 {
+		return fmt.Errorf("podcasts dosent require any arguments, found %v arguments", cmd.Arguments)
+	}
+
+	podcasts, err := s.db.GetPodcastsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get podcasts: %w", err)
+	}
+	if len(podcasts) == 0 {
+		fmt.Println("None of the feeds you follow have episodes yet")
+		return nil
+	}
+
+	location := userLocation(user)
+	for _, podcast := range podcasts {
+		fmt.Printf("* %s (%s)\n", podcast.FeedName, podcast.FeedUrl)
+		fmt.Printf("  %d episodes, %d unplayed, %d downloaded, newest %s\n",
+			podcast.EpisodeCount,
+			podcast.EpisodeCount-podcast.PlayedCount,
+			podcast.DownloadedCount,
+			formatTime(podcast.NewestCreatedAt, location),
+		)
+	}
+	return nil
+}
+
+func handlerEpisodes(s *state, cmd command, user database.User) error {
+	args, feedURL, err := popFlagValue(cmd.Arguments, "--feed")
+	if err != nil {
+		return err
+	}
+	args, unplayed := popFlag(args, "--unplayed")
+	args, downloaded := popFlag(args, "--downloaded")
+
+	if len(args) > 1 {
+		return fmt.Errorf("episodes takes at most 1 argument (limit), found %v arguments", len(args))
+	}
+	limit := 10
+	if len(args) > 0 {
+		limit, err = strconv.Atoi(args[0])
+		if err != nil || limit < 1 || limit > math.MaxInt32 {
+			return fmt.Errorf("invalid limit: %s", args[0])
+		}
+	}
+
+	getEpisodesForUserParams := database.GetEpisodesForUserParams{
+		UserID:         user.ID,
+		FeedUrl:        sql.NullString{String: feedURL, Valid: feedURL != ""},
+		UnplayedOnly:   unplayed,
+		DownloadedOnly: downloaded,
+		Limit:          int32(limit),
+	}
+	episodes, err := s.db.GetEpisodesForUser(context.Background(), getEpisodesForUserParams)
+	if err != nil {
+		return fmt.Errorf("failed to get episodes: %w", err)
+	}
+
+	location := userLocation(user)
+	for _, episode := range episodes {
+		published := "unknown"
+		if episode.PublishedAt.Valid {
+			published = formatTime(episode.PublishedAt.Time, location)
+		}
+		marks := ""
+		if !episode.PlayedAt.Valid {
+			marks += " [unplayed]"
+		}
+		if episode.DownloadedAt.Valid {
+			marks += " [downloaded]"
+		}
+		fmt.Printf("Title: %s%s\nPodcast: %s\nURL: %s\nPublished: %s\n", episode.Title, marks, episode.FeedName, episode.Url, published)
+		if details := episodeDetails(episode); details != "" {
+			fmt.Printf("Episode: %s\n", details)
+		}
+		media := database.PostEnclosure{MediaType: episode.MediaType, Length: episode.MediaLength}
+		fmt.Printf("Media: %s%s\n", episode.MediaUrl, enclosureDetails(media))
+		if episode.DownloadPath.Valid {
+			fmt.Printf("File: %s\n", episode.DownloadPath.String)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// episodeDetails summarises the numbering, length and rating of an
+// episode, such as "S2 E5, 1:02:03, explicit".
+func episodeDetails(episode database.GetEpisodesForUserRow) string {
+	var details []string
+	if number := episodeNumber(episode); number != "" {
+		details = append(details, number)
+	}
+	if episode.DurationSeconds.Valid {
+		details = append(details, formatDuration(episode.DurationSeconds.Int32))
+	}
+	if episode.Explicit.Valid && episode.Explicit.Bool {
+		details = append(details, "explicit")
+	}
+	return strings.Join(details, ", ")
+}
+
+func episodeNumber(episode database.GetEpisodesForUserRow) string {
+	var parts []string
+	if episode.SeasonNumber.Valid {
+		parts = append(parts, fmt.Sprintf("S%d", episode.SeasonNumber.Int32))
+	}
+	if episode.EpisodeNumber.Valid {
+		parts = append(parts, fmt.Sprintf("E%d", episode.EpisodeNumber.Int32))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatDuration prints seconds as M:SS, or H:MM:SS from an hour up.
+func formatDuration(seconds int32) string {
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+func handlerPlayed(s *state, cmd command, user database.User) error {
+	return forEachPostURL(s, cmd.Name, cmd.Arguments, func(post database.Post) error {
+		err := setPostPlayed(context.Background(), s.db, user.ID, post.ID, true)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Marked '%s' as played\n", post.Title)
+		return nil
+	})
+}
+
+func handlerUnplayed(s *state, cmd command, user database.User) error {
+	return forEachPostURL(s, cmd.Name, cmd.Arguments, func(post database.Post) error {
+		err := setPostPlayed(context.Background(), s.db, user.ID, post.ID, false)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Marked '%s' as unplayed\n", post.Title)
+		return nil
+	})
+}
+
+func setPostPlayed(ctx context.Context, db database.Querier, userID, postID uuid.UUID, played bool) error {
+	markPostPlayedParams := database.MarkPostPlayedParams{
+		UserID:   userID,
+		PostID:   postID,
+		PlayedAt: sql.NullTime{Time: time.Now().UTC(), Valid: played},
+	}
+	err := db.MarkPostPlayed(ctx, markPostPlayedParams)
+	if err != nil {
+		return fmt.Errorf("failed to update played state: %w", err)
+	}
+	return nil
+}
+
+// handlerDownload saves episodes to the download directory, either the
+// ones given by post URL or, with --unplayed, the newest unplayed episodes
+// that are not downloaded yet.
+func handlerDownload(s *state, cmd command, user database.User) error {
+	args, dir, err := popFlagValue(cmd.Arguments, "--dir")
+	if err != nil {
+		return err
+	}
+	args, feedURL, err := popFlagValue(args, "--feed")
+	if err != nil {
+		return err
+	}
+	args, limitValue, err := popFlagValue(args, "--limit")
+	if err != nil {
+		return err
+	}
+	args, unplayed := popFlag(args, "--unplayed")
+
+	if dir == "" {
+		dir, err = downloadDir(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	var episodes []database.GetEpisodesForUserRow
+	if unplayed {
+		if len(args) != 0 {
+			return fmt.Errorf("download --unplayed dosent take post URLs, found %v arguments", args)
+		}
+		limit := 5
+		if limitValue != "" {
+			limit, err = strconv.Atoi(limitValue)
+			if err != nil || limit < 1 || limit > math.MaxInt32 {
+				return fmt.Errorf("invalid limit: %s", limitValue)
+			}
+		}
+		getEpisodesForUserParams := database.GetEpisodesForUserParams{
+			UserID:            user.ID,
+			FeedUrl:           sql.NullString{String: feedURL, Valid: feedURL != ""},
+			UnplayedOnly:      true,
+			NotDownloadedOnly: true,
+			Limit:             int32(limit),
+		}
+		episodes, err = s.db.GetEpisodesForUser(context.Background(), getEpisodesForUserParams)
+		if err != nil {
+			return fmt.Errorf("failed to get episodes: %w", err)
+		}
+		if len(episodes) == 0 {
+			fmt.Println("No new episodes to download")
+			return nil
+		}
+	} else {
+		if feedURL != "" || limitValue != "" {
+			return errors.New("--feed and --limit can only be used with download --unplayed")
+		}
+		err = forEachPostURL(s, cmd.Name, args, func(post database.Post) error {
+			getEpisodesForUserParams := database.GetEpisodesForUserParams{
+				UserID: user.ID,
+				PostID: uuid.NullUUID{UUID: post.ID, Valid: true},
+				Limit:  1,
+			}
+			found, err := s.db.GetEpisodesForUser(context.Background(), getEpisodesForUserParams)
+			if err != nil {
+				return fmt.Errorf("failed to get episode: %w", err)
+			}
+			if len(found) == 0 {
+				return fmt.Errorf("'%s' is not an episode of a podcast you follow", post.Title)
+			}
+			episodes = append(episodes, found[0])
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for _, episode := range episodes {
+		err := downloadEpisode(s, user, episode, dir)
+		if err != nil {
+			fmt.Printf("Failed to download '%s': %v\n", episode.Title, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed", failed, len(episodes))
+	}
+	return nil
+}
+
+func downloadEpisode(s *state, user database.User, episode database.GetEpisodesForUserRow, dir string) error {
+	filePath := filepath.Join(dir, safeFileName(episode.FeedName, "podcast"), episodeFileName(episode))
+	if _, err := os.Stat(filePath); err == nil {
+		fmt.Printf("Already downloaded '%s' to %s\n", episode.Title, filePath)
+		return markEpisodeDownloaded(s, user, episode, filePath)
+	}
+
+	err := os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	fmt.Printf("Downloading '%s'...\n", episode.Title)
+	result, err := download.File(context.Background(), episode.MediaUrl, filePath)
+	if err != nil {
+		return err
+	}
+	if result.Resumed > 0 {
+		fmt.Printf("Downloaded %s to %s (resumed after %s)\n", formatSize(result.Size), filePath, formatSize(result.Resumed))
+	} else {
+		fmt.Printf("Downloaded %s to %s\n", formatSize(result.Size), filePath)
+	}
+	return markEpisodeDownloaded(s, user, episode, filePath)
+}
+
+func markEpisodeDownloaded(s *state, user database.User, episode database.GetEpisodesForUserRow, filePath string) error {
+	markPostDownloadedParams := database.MarkPostDownloadedParams{
+		UserID:       user.ID,
+		PostID:       episode.ID,
+		DownloadedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		DownloadPath: sql.NullString{String: filePath, Valid: true},
+	}
+	err := s.db.MarkPostDownloaded(context.Background(), markPostDownloadedParams)
+	if err != nil {
+		return fmt.Errorf("failed to update downloaded state: %w", err)
+	}
+	return nil
+}
+
+// downloadDir is download_dir from the config, or ~/Podcasts.
+func downloadDir(s *state) (string, error) {
+	if s.config.DownloadDir != "" {
+		return s.config.DownloadDir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory, set download_dir in the config or pass --dir: %w", err)
+	}
+	return filepath.Join(home, "Podcasts"), nil
+}
+
+// episodeFileName names the file of an episode after its number and title,
+// keeping the extension of the media URL or, failing that, one matching
+// its type.
+func episodeFileName(episode database.GetEpisodesForUserRow) string {
+	name := episode.Title
+	if number := episodeNumber(episode); number != "" {
+		name = number + " " + name
+	}
+	name = safeFileName(name, episode.ID.String())
+
+	ext := ""
+	if mediaURL, err := url.Parse(episode.MediaUrl); err == nil {
+		ext = path.Ext(mediaURL.Path)
+	}
+	if len(ext) < 2 || len(ext) > 5 {
+		ext = ""
+		if exts, err := mime.ExtensionsByType(episode.MediaType); err == nil && len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	return name + ext
+}
+
+// safeFileName replaces the characters that are not allowed in file names
+// on common systems and shortens long names. An empty result becomes
+// fallback.
+func safeFileName(name, fallback string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 100 {
+		name = string(runes[:100])
+	}
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return fallback
+	}
+	return name
+}
@@ -0,0 +1,111 @@
+package rssFeed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// utf8BOM is written by some editors at the start of UTF-8 files.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+var xmlDeclEncoding = regexp.MustCompile(`^\s*<\?xml[^>]*\sencoding\s*=\s*["']([^"']+)["']`)
+
+// newDecoder returns an XML decoder for body that first converts it to
+// UTF-8. The charset of the Content-Type header wins, as the server may
+// have re-encoded the file, then comes the encoding in the XML declaration.
+// A header that claims UTF-8 for a body that is not is ignored, since many
+// servers add it to everything they send. A body that is not UTF-8 and
+// names no encoding at all is read as Windows-1252, the usual culprit.
+// UTF-16 is recognised from the body itself before any of that, as neither
+// label can be read without knowing it.
+func newDecoder(body []byte, contentType string) *xml.Decoder {
+	if decoded, ok := decodeUTF16(body); ok {
+		body = decoded
+	}
+	body = bytes.TrimPrefix(body, utf8BOM)
+
+	label := headerCharset(contentType)
+	if label == "" || (isUTF8(label) && !utf8.Valid(body)) {
+		label = declaredEncoding(body)
+	}
+	// A body labelled UTF-16 that is not is mislabelled, or was converted
+	// above and still declares what it was.
+	if isUTF16(label) {
+		label = ""
+	}
+	if label == "" && !utf8.Valid(body) {
+		label = "windows-1252"
+	}
+
+	var r io.Reader = bytes.NewReader(body)
+	if encoding, name := charset.Lookup(label); encoding != nil && name != "utf-8" {
+		r = encoding.NewDecoder().Reader(r)
+	}
+	decoder := xml.NewDecoder(r)
+	// The body is UTF-8 by now, whatever its declaration says.
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return decoder
+}
+
+// decodeUTF16 converts body to UTF-8 if it is UTF-16, which it tells from
+// the byte order mark or, without one, from the NUL byte next to the
+// opening '<' of the document.
+func decodeUTF16(body []byte) ([]byte, bool) {
+	if len(body) < 2 {
+		return nil, false
+	}
+	var endianness unicode.Endianness
+	switch {
+	case body[0] == 0xfe && body[1] == 0xff:
+		endianness = unicode.BigEndian
+	case body[0] == 0xff && body[1] == 0xfe:
+		endianness = unicode.LittleEndian
+	case body[0] == 0 && body[1] != 0:
+		endianness = unicode.BigEndian
+	case body[0] != 0 && body[1] == 0:
+		endianness = unicode.LittleEndian
+	default:
+		return nil, false
+	}
+	decoded, err := unicode.UTF16(endianness, unicode.UseBOM).NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+func headerCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(params["charset"])
+}
+
+func declaredEncoding(body []byte) string {
+	match := xmlDeclEncoding.FindSubmatch(body[:min(len(body), 512)])
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSpace(string(match[1]))
+}
+
+func isUTF8(label string) bool {
+	_, name := charset.Lookup(label)
+	return name == "utf-8"
+}
+
+func isUTF16(label string) bool {
+	_, name := charset.Lookup(label)
+	return strings.HasPrefix(name, "utf-16")
+}
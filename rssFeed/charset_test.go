@@ -0,0 +1,85 @@
+package rssFeed
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestNewDecoder(t *testing.T) {
+	decl := func(encoding string) string {
+		return `<?xml version="1.0" encoding="` + encoding + `"?>`
+	}
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+	}{
+		{"utf-8", `<rss><channel><title>café</title></channel></rss>`, "", "café"},
+		{"utf-8 with bom", "\xef\xbb\xbf<rss><channel><title>café</title></channel></rss>", "", "café"},
+		{"header charset", "<rss><channel><title>caf\xe9</title></channel></rss>", "application/rss+xml; charset=ISO-8859-1", "café"},
+		{"header charset over declaration", decl("UTF-8") + "<rss><channel><title>caf\xe9</title></channel></rss>", "text/xml; charset=latin1", "café"},
+		{"declared encoding", decl("ISO-8859-1") + "<rss><channel><title>caf\xe9</title></channel></rss>", "", "café"},
+		{"declared encoding over false utf-8 header", decl("windows-1251") + "<rss><channel><title>\xcf\xf0\xe8\xe2\xe5\xf2</title></channel></rss>", "text/xml; charset=utf-8", "Привет"},
+		{"windows-1251 header", "<rss><channel><title>\xcf\xf0\xe8\xe2\xe5\xf2</title></channel></rss>", "text/xml; charset=windows-1251", "Привет"},
+		{"windows-1252 default", "<rss><channel><title>\x80 caf\xe9</title></channel></rss>", "", "€ café"},
+		{"utf-8 declared as utf-16", decl("UTF-16") + "<rss><channel><title>café</title></channel></rss>", "", "café"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RSSFeed
+			if err := newDecoder([]byte(tt.body), tt.contentType).Decode(&got); err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if got.Channel.Title != tt.want {
+				t.Errorf("title = %q, want %q", got.Channel.Title, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDecoderUTF16(t *testing.T) {
+	const doc = `<?xml version="1.0" encoding="UTF-16"?><rss><channel><title>Привет café</title></channel></rss>`
+	tests := []struct {
+		name        string
+		endianness  unicode.Endianness
+		bom         unicode.BOMPolicy
+		contentType string
+	}{
+		{"little endian with bom", unicode.LittleEndian, unicode.UseBOM, ""},
+		{"big endian with bom", unicode.BigEndian, unicode.UseBOM, ""},
+		{"little endian without bom", unicode.LittleEndian, unicode.IgnoreBOM, ""},
+		{"big endian without bom", unicode.BigEndian, unicode.IgnoreBOM, ""},
+		{"header charset", unicode.LittleEndian, unicode.UseBOM, "text/xml; charset=utf-16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := unicode.UTF16(tt.endianness, tt.bom).NewEncoder().Bytes([]byte(doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got RSSFeed
+			if err := newDecoder(body, tt.contentType).Decode(&got); err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if got.Channel.Title != "Привет café" {
+				t.Errorf("title = %q, want %q", got.Channel.Title, "Привет café")
+			}
+
+			// The lenient parse has to convert before it repairs anything.
+			malformed, err := unicode.UTF16(tt.endianness, tt.bom).NewEncoder().Bytes([]byte(strings.Replace(doc, "café", "café & co", 1)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			feed, warning, err := parseFeed(malformed, tt.contentType)
+			if err != nil {
+				t.Fatalf("parseFeed failed: %v", err)
+			}
+			if warning == "" || feed.Channel.Title != "Привет café & co" {
+				t.Errorf("lenient title = %q with warning %q, want %q with a warning", feed.Channel.Title, warning, "Привет café & co")
+			}
+		})
+	}
+}
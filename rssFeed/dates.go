@@ -0,0 +1,144 @@
+package rssFeed
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNoDate is returned for items that carry no date at all.
+var ErrNoDate = errors.New("item has no date")
+
+// dateLayouts are tried in order once the weekday is removed and a named
+// zone is replaced by its offset. Layouts with four digit years come before
+// their two digit RFC 822 counterparts, numeric zones may be written with
+// or without a colon, and a time without a zone is taken as UTC.
+// Fractional seconds are accepted after any seconds field.
+var dateLayouts = []string{
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04 -07:00",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 Jan 06 15:04:05 -07:00",
+	"2 Jan 06 15:04 -07:00",
+	"2 Jan 06 15:04:05",
+	"2 Jan 06 15:04",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04 -0700",
+	"2 January 2006 15:04:05 -07:00",
+	"2-Jan-2006 15:04:05 -0700",
+	"2-Jan-06 15:04:05 -0700",
+	"Jan 2 2006 15:04:05 -0700",
+	"Jan 2 2006 15:04 -0700",
+	"Jan 2 2006 15:04:05 -07:00",
+	"Jan 2 2006 15:04:05",
+	"Jan 2 2006 15:04",
+	"Jan 2 2006",
+	"Jan 2 15:04:05 2006",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2 Jan 2006",
+	"2 January 2006",
+}
+
+// zoneOffsets are the named zones seen in feeds. Go only knows the
+// abbreviations of the local zone and would read any other one as UTC.
+var zoneOffsets = map[string]string{
+	"UT": "+0000", "UTC": "+0000", "GMT": "+0000", "Z": "+0000",
+	"EST": "-0500", "EDT": "-0400", "CST": "-0600", "CDT": "-0500",
+	"MST": "-0700", "MDT": "-0600", "PST": "-0800", "PDT": "-0700",
+	"AKST": "-0900", "AKDT": "-0800", "HST": "-1000",
+	"WET": "+0000", "WEST": "+0100", "BST": "+0100", "IST": "+0530",
+	"CET": "+0100", "CEST": "+0200", "MET": "+0100", "MEST": "+0200",
+	"EET": "+0200", "EEST": "+0300", "MSK": "+0300",
+	"JST": "+0900", "KST": "+0900", "HKT": "+0800", "SGT": "+0800",
+	"AWST": "+0800", "ACST": "+0930", "AEST": "+1000", "AEDT": "+1100",
+	"NZST": "+1200", "NZDT": "+1300",
+}
+
+var weekdays = []string{
+	"mon", "tue", "tues", "wed", "thu", "thur", "thurs", "fri", "sat", "sun",
+	"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday",
+}
+
+// PublishedTime returns when the item was published, from pubDate or else
+// dc:date. Items without either return ErrNoDate.
+func (item RSSItem) PublishedTime() (time.Time, error) {
+	date := strings.TrimSpace(item.PubDate)
+	if date == "" {
+		date = strings.TrimSpace(item.Date)
+	}
+	if date == "" {
+		return time.Time{}, ErrNoDate
+	}
+	return ParseDate(date)
+}
+
+// ParseDate parses the date formats found in real feeds: RFC 822 and 1123
+// with or without the weekday, seconds or a four digit year, named or
+// numeric zones such as -0400 and -04:00, and ISO 8601 with or without a
+// zone.
+func ParseDate(date string) (time.Time, error) {
+	fields := strings.Fields(date)
+	if len(fields) == 0 {
+		return time.Time{}, ErrNoDate
+	}
+
+	// The weekday adds nothing, and feeds get its punctuation wrong.
+	first := strings.ToLower(strings.TrimRight(fields[0], ",."))
+	for _, weekday := range weekdays {
+		if first == weekday {
+			fields = fields[1:]
+			break
+		}
+	}
+
+	if len(fields) > 1 {
+		last := fields[len(fields)-1]
+		if offset, ok := zoneOffsets[strings.ToUpper(last)]; ok {
+			fields[len(fields)-1] = offset
+		} else if isLetters(last) {
+			// An unknown zone name, read the time as UTC rather than fail.
+			fields = fields[:len(fields)-1]
+		}
+	}
+
+	for i, field := range fields {
+		// Go only knows three letter month names, except for the full ones.
+		if strings.EqualFold(field, "Sept") {
+			fields[i] = "Sep"
+		}
+		fields[i] = strings.TrimRight(fields[i], ",")
+	}
+
+	normalized := strings.Join(fields, " ")
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported date format: %s", date)
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
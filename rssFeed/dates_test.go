@@ -0,0 +1,113 @@
+package rssFeed
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	plus2 := time.FixedZone("", 2*3600)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		// RFC 822 and 1123, with and without the weekday.
+		{"Mon, 02 Jan 2006 15:04:05 -0700", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"02 Jan 2006 15:04:05 +0200", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"Mon, 2 Jan 2006 15:04 +0200", time.Date(2006, 1, 2, 15, 4, 0, 0, plus2)},
+		{"Mon, 02 Jan 2006 15:04:05", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"02 Jan 2006 15:04", time.Date(2006, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"Mon, 02 Jan 2006 15:04:05.123 +0200", time.Date(2006, 1, 2, 15, 4, 5, 123e6, plus2)},
+		// Offsets with a colon.
+		{"Mon, 02 Jan 2006 15:04:05 +02:00", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"02 Jan 2006 15:04 +02:00", time.Date(2006, 1, 2, 15, 4, 0, 0, plus2)},
+		{"Mon, 02 Jan 06 15:04:05 +02:00", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"02 Jan 06 15:04 +02:00", time.Date(2006, 1, 2, 15, 4, 0, 0, plus2)},
+		{"2 January 2006 15:04:05 +02:00", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"Jan 2 2006 15:04:05 +02:00", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"2006-01-02 15:04:05 +02:00", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		// Two digit years.
+		{"Mon, 02 Jan 06 15:04:05 +0200", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"02 Jan 06 15:04 +0200", time.Date(2006, 1, 2, 15, 4, 0, 0, plus2)},
+		{"02 Jan 06 15:04:05", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"02 Jan 06 15:04", time.Date(2006, 1, 2, 15, 4, 0, 0, time.UTC)},
+		// Full month names, dashes and month first.
+		{"Monday, 2 January 2006 15:04:05 +0200", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"2 January 2006 15:04 +0200", time.Date(2006, 1, 2, 15, 4, 0, 0, plus2)},
+		{"02-Jan-2006 15:04:05 +0200", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"02-Jan-06 15:04:05 +0200", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"Jan 2, 2006 15:04:05 +0200", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"Jan 2 2006 15:04 +0200", time.Date(2006, 1, 2, 15, 4, 0, 0, plus2)},
+		{"Jan 2 2006 15:04:05", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"Jan 2 2006 15:04", time.Date(2006, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"Jan 2, 2006", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"Mon Jan 2 15:04:05 2006", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2 Jan 2006", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2 January 2006", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		// ISO 8601.
+		{"2006-01-02T15:04:05Z", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2006-01-02T15:04:05+02:00", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"2006-01-02T15:04:05.999+02:00", time.Date(2006, 1, 2, 15, 4, 5, 999e6, plus2)},
+		{"2006-01-02T15:04:05+0200", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"2006-01-02T15:04:05", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2006-01-02T15:04+02:00", time.Date(2006, 1, 2, 15, 4, 0, 0, plus2)},
+		{"2006-01-02T15:04", time.Date(2006, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"2006-01-02 15:04:05Z", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2006-01-02 15:04:05 +0200", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"2006-01-02 15:04:05", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2006-01-02 15:04", time.Date(2006, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"2006-01-02", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		// Named zones, known and unknown.
+		{"Mon, 02 Jan 2006 15:04:05 GMT", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"Mon, 02 Jan 2006 15:04:05 EDT", time.Date(2006, 1, 2, 19, 4, 5, 0, time.UTC)},
+		{"Mon, 02 Jan 2006 15:04:05 pst", time.Date(2006, 1, 2, 23, 4, 5, 0, time.UTC)},
+		{"Mon, 02 Jan 2006 15:04:05 CEST", time.Date(2006, 1, 2, 13, 4, 5, 0, time.UTC)},
+		{"Mon, 02 Jan 2006 15:04:05 XYZT", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		// Sloppy punctuation and Sept.
+		{"Mon., 02 Jan 2006 15:04:05 +0200", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"Tues 02 Jan 2006 15:04:05 +0200", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"  Mon,  02 Jan 2006  15:04:05 +0200 ", time.Date(2006, 1, 2, 15, 4, 5, 0, plus2)},
+		{"Thu, 07 Sept 2006 15:04:05 +0200", time.Date(2006, 9, 7, 15, 4, 5, 0, plus2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDate(tt.in)
+			if err != nil {
+				t.Fatalf("ParseDate(%q) failed: %v", tt.in, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	for _, in := range []string{"yesterday", "32 Jan 2006 15:04:05 +0200", "2006-13-02", "Mon, 02 Jan"} {
+		if got, err := ParseDate(in); err == nil {
+			t.Errorf("ParseDate(%q) = %v, want an error", in, got)
+		}
+	}
+	if _, err := ParseDate("   "); !errors.Is(err, ErrNoDate) {
+		t.Errorf("ParseDate of a blank date = %v, want ErrNoDate", err)
+	}
+}
+
+func TestPublishedTime(t *testing.T) {
+	item := RSSItem{Date: "2006-01-02T15:04:05Z"}
+	got, err := item.PublishedTime()
+	if err != nil || !got.Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("PublishedTime() with dc:date = %v, %v", got, err)
+	}
+
+	item.PubDate = "Tue, 03 Jan 2006 15:04:05 GMT"
+	got, err = item.PublishedTime()
+	if err != nil || !got.Equal(time.Date(2006, 1, 3, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("PublishedTime() with pubDate = %v, %v, want pubDate to win", got, err)
+	}
+
+	if _, err := (RSSItem{}).PublishedTime(); !errors.Is(err, ErrNoDate) {
+		t.Errorf("PublishedTime() without a date = %v, want ErrNoDate", err)
+	}
+}
@@ -7,23 +7,79 @@ import (
 	"html"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/Romasav/gator/internal/sanitize"
 )
 
 type RSSFeed struct {
+	// XMLName makes documents other than RSS, such as the HTML page of a
+	// site that moved its feed, fail to decode instead of decoding empty.
+	XMLName xml.Name `xml:"rss"`
 	Channel struct {
+		XMLBase     string    `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
 		Title       string    `xml:"title"`
 		Link        string    `xml:"link"`
 		Description string    `xml:"description"`
 		Items       []RSSItem `xml:"item"`
+		// Image is the podcast artwork, used for episodes without their own.
+		Image ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
 	} `xml:"channel"`
+	// ParseWarning describes what was wrong with a feed that could only be
+	// parsed leniently. It is empty for well-formed feeds.
+	ParseWarning string `xml:"-"`
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
+	XMLBase string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	// GUID identifies the item. Feeds that give no link often put the
+	// permalink here instead.
+	GUID        string `xml:"guid"`
 	Description string `xml:"description"`
 	PubDate     string `xml:"pubDate"`
+	// Date is dc:date, which some feeds send instead of pubDate.
+	Date string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	// Content is the full HTML body from content:encoded, if the feed
+	// sends one next to the description.
+	Content    string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author     string         `xml:"author"`
+	Creator    string         `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories []string       `xml:"category"`
+	Enclosures []RSSEnclosure `xml:"enclosure"`
+	ITunesItem
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// AuthorName returns the author of the item, preferring dc:creator, which
+// holds a plain name, over author, which RSS defines as an email address.
+func (item RSSItem) AuthorName() string {
+	if creator := strings.TrimSpace(item.Creator); creator != "" {
+		return creator
+	}
+	return strings.TrimSpace(item.Author)
+}
+
+// CategoryNames returns the item's non-empty categories without
+// duplicates, in feed order.
+func (item RSSItem) CategoryNames() []string {
+	var names []string
+	for _, category := range item.Categories {
+		category = strings.TrimSpace(category)
+		if category != "" && !slices.Contains(names, category) {
+			names = append(names, category)
+		}
+	}
+	return names
 }
 
 func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
@@ -53,19 +109,42 @@ func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	var feed RSSFeed
-	err = xml.Unmarshal(body, &feed)
+	feed, warning, err := parseFeed(body, resp.Header.Get("Content-Type"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal XML: %w", err)
+		return nil, err
 	}
+	feed.ParseWarning = warning
 
 	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
 	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
 
+	// Relative links are resolved against the channel's xml:base, else its
+	// site link, else the address the feed was fetched from.
+	base := resp.Request.URL
+	if feed.Channel.XMLBase != "" {
+		base = baseURL(base, feed.Channel.XMLBase)
+	} else if link, err := url.Parse(strings.TrimSpace(feed.Channel.Link)); err == nil && (link.Scheme == "http" || link.Scheme == "https") && link.Host != "" {
+		base = link
+	}
+	feed.Channel.Link = resolveURL(base, feed.Channel.Link)
+	feed.Channel.Image.Href = resolveURL(base, feed.Channel.Image.Href)
+
 	for i, item := range feed.Channel.Items {
+		itemBase := baseURL(base, item.XMLBase)
+		feed.Channel.Items[i].Link = itemLink(itemBase, item)
 		feed.Channel.Items[i].Title = html.UnescapeString(item.Title)
-		feed.Channel.Items[i].Description = html.UnescapeString(item.Description)
+		feed.Channel.Items[i].Description = sanitize.HTML(html.UnescapeString(item.Description), itemBase)
+		feed.Channel.Items[i].Content = sanitize.HTML(item.Content, itemBase)
+		feed.Channel.Items[i].ITunesItem.Image.Href = resolveURL(itemBase, item.ITunesItem.Image.Href)
+		for j, enclosure := range item.Enclosures {
+			feed.Channel.Items[i].Enclosures[j].URL = resolveURL(itemBase, enclosure.URL)
+		}
+		feed.Channel.Items[i].Author = html.UnescapeString(item.Author)
+		feed.Channel.Items[i].Creator = html.UnescapeString(item.Creator)
+		for j, category := range item.Categories {
+			feed.Channel.Items[i].Categories[j] = html.UnescapeString(category)
+		}
 	}
 
-	return &feed, nil
+	return feed, nil
 }
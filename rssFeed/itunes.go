@@ -0,0 +1,127 @@
+package rssFeed
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ITunesImage is the itunes:image element, which keeps its URL in the href
+// attribute rather than in its text.
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// ITunesItem holds the podcast fields Apple's itunes namespace adds to an
+// item.
+type ITunesItem struct {
+	Duration string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	Episode  string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+	Season   string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd season"`
+	Image    ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	Explicit string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd explicit"`
+}
+
+// DurationSeconds parses itunes:duration, which feeds write as plain
+// seconds, MM:SS or HH:MM:SS, sometimes with a fraction of a second that is
+// dropped. It reports false if the item has no usable duration.
+func (it ITunesItem) DurationSeconds() (int32, bool) {
+	duration := strings.TrimSpace(it.Duration)
+	if duration == "" {
+		return 0, false
+	}
+	parts := strings.Split(duration, ":")
+	if len(parts) > 3 {
+		return 0, false
+	}
+
+	last, fraction, found := strings.Cut(parts[len(parts)-1], ".")
+	if found && !isDigits(fraction) {
+		return 0, false
+	}
+	parts[len(parts)-1] = last
+
+	var seconds int64
+	for i, part := range parts {
+		if !isDigits(part) {
+			return 0, false
+		}
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		// Only the leading part may count past a minute or an hour.
+		if i > 0 && n >= 60 {
+			return 0, false
+		}
+		seconds = seconds*60 + n
+		if seconds > math.MaxInt32 {
+			return 0, false
+		}
+	}
+	return int32(seconds), true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// EpisodeNumber returns itunes:episode, if the item has a valid one.
+func (it ITunesItem) EpisodeNumber() (int32, bool) {
+	return parsePositive(it.Episode)
+}
+
+// SeasonNumber returns itunes:season, if the item has a valid one.
+func (it ITunesItem) SeasonNumber() (int32, bool) {
+	return parsePositive(it.Season)
+}
+
+// IsExplicit parses itunes:explicit. Besides the current true and false the
+// spec used to allow yes, no and clean, and feeds still send those.
+func (it ITunesItem) IsExplicit() (explicit bool, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(it.Explicit)) {
+	case "true", "yes", "explicit":
+		return true, true
+	case "false", "no", "clean":
+		return false, true
+	}
+	return false, false
+}
+
+func parsePositive(s string) (int32, bool) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return int32(n), true
+}
+
+// MediaEnclosure returns the enclosure a podcast client would play: the
+// first audio or video file, or else the first enclosure with a URL.
+func (item RSSItem) MediaEnclosure() (RSSEnclosure, bool) {
+	var fallback *RSSEnclosure
+	for i, enclosure := range item.Enclosures {
+		if strings.TrimSpace(enclosure.URL) == "" {
+			continue
+		}
+		mediaType := strings.ToLower(enclosure.Type)
+		if strings.HasPrefix(mediaType, "audio/") || strings.HasPrefix(mediaType, "video/") {
+			return enclosure, true
+		}
+		if fallback == nil {
+			fallback = &item.Enclosures[i]
+		}
+	}
+	if fallback == nil {
+		return RSSEnclosure{}, false
+	}
+	return *fallback, true
+}
@@ -0,0 +1,48 @@
+package rssFeed
+
+import "testing"
+
+func TestDurationSeconds(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int32
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"  ", 0, false},
+		{"0", 0, true},
+		{"90", 90, true},
+		{" 3600 ", 3600, true},
+		{"1:30", 90, true},
+		{"90:00", 5400, true},
+		{"01:02:03", 3723, true},
+		{"100:00:00", 360000, true},
+		{"1:02:03.250", 3723, true},
+		{"123.9", 123, true},
+		{"2147483647", 2147483647, true},
+		{"2147483648", 0, false},
+		{"596523:14:08", 0, false},
+		{"99999999999999999999", 0, false},
+		{"1e400", 0, false},
+		{"Inf", 0, false},
+		{"NaN", 0, false},
+		{"-5", 0, false},
+		{"+5", 0, false},
+		{"1:60", 0, false},
+		{"1:60:00", 0, false},
+		{"1:00:60", 0, false},
+		{"1.5:00", 0, false},
+		{"1:", 0, false},
+		{":30", 0, false},
+		{"1:2:3:4", 0, false},
+		{"12.", 0, false},
+		{"12.5s", 0, false},
+		{"1 h", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ITunesItem{Duration: tt.in}.DurationSeconds()
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("DurationSeconds(%q) = %d, %v, want %d, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
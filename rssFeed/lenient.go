@@ -0,0 +1,121 @@
+package rssFeed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+)
+
+// bareAmpersand matches every ampersand, along with the reference it
+// starts if it starts one, so the ones that do not can be escaped.
+var bareAmpersand = regexp.MustCompile(`&(?:[A-Za-z][A-Za-z0-9]{0,31};|#[0-9]{1,7};|#[xX][0-9a-fA-F]{1,6};)?`)
+
+var (
+	xmlDecl    = []byte("<?xml")
+	rssStart   = []byte("<rss")
+	cdataStart = []byte("<![CDATA[")
+	cdataEnd   = []byte("]]>")
+	rssEnd     = []byte("</rss>")
+)
+
+// parseFeed decodes body, falling back to a tolerant parse when the feed is
+// not well-formed XML. The fallback repairs what it can up front, then
+// reads the feed with a non-strict decoder that knows the HTML entities and
+// keeps the items decoded before any error it still runs into. The
+// returned warning says what was wrong; it is empty for a clean feed.
+func parseFeed(body []byte, contentType string) (*RSSFeed, string, error) {
+	var feed RSSFeed
+	strictErr := newDecoder(body, contentType).Decode(&feed)
+	if strictErr == nil {
+		return &feed, "", nil
+	}
+
+	// cleanXML works on the bytes, which it would mangle if they were UTF-16.
+	if decoded, ok := decodeUTF16(body); ok {
+		body = decoded
+	}
+	feed = RSSFeed{}
+	decoder := newDecoder(cleanXML(body), contentType)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	err := decoder.Decode(&feed)
+	if feed.Channel.Title == "" && len(feed.Channel.Items) == 0 {
+		// Nothing in there looks like a feed.
+		return nil, "", fmt.Errorf("failed to unmarshal XML: %w", strictErr)
+	}
+
+	warning := fmt.Sprintf("malformed XML (%v), parsed leniently", strictErr)
+	if err != nil {
+		warning = fmt.Sprintf("malformed XML (%v), only the first %d items could be read", err, len(feed.Channel.Items))
+	}
+	return &feed, warning, nil
+}
+
+// cleanXML fixes the mistakes that most often make feeds unparseable:
+// anything before the first tag, such as byte order marks, control
+// characters XML does not allow, ampersands that are not escaped, and
+// anything after the closing </rss> tag. CDATA sections are left as they
+// are, as their content is literal.
+func cleanXML(body []byte) []byte {
+	// The document starts at its declaration, or at <rss> without one,
+	// even if what a broken server printed before it has tags too.
+	start := bytes.Index(body, xmlDecl)
+	if start < 0 {
+		start = bytes.Index(body, rssStart)
+	}
+	if start < 0 {
+		start = bytes.IndexByte(body, '<')
+	}
+	if start > 0 {
+		body = body[start:]
+	}
+	if end := bytes.LastIndex(body, rssEnd); end >= 0 {
+		body = body[:end+len(rssEnd)]
+	}
+
+	out := make([]byte, 0, len(body)+len(body)/50)
+	for len(body) > 0 {
+		next := bytes.Index(body, cdataStart)
+		if next < 0 {
+			next = len(body)
+		}
+		out = append(out, escapeAmpersands(dropControlChars(body[:next]))...)
+		body = body[next:]
+		if len(body) == 0 {
+			break
+		}
+
+		end := bytes.Index(body, cdataEnd)
+		if end < 0 {
+			end = len(body)
+		} else {
+			end += len(cdataEnd)
+		}
+		out = append(out, dropControlChars(body[:end])...)
+		body = body[end:]
+	}
+	return out
+}
+
+func escapeAmpersands(b []byte) []byte {
+	return bareAmpersand.ReplaceAllFunc(b, func(match []byte) []byte {
+		if len(match) == 1 {
+			return []byte("&amp;")
+		}
+		return match
+	})
+}
+
+// dropControlChars removes the control characters below space other than
+// tab, newline and carriage return, which no XML document may contain.
+func dropControlChars(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
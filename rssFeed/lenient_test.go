@@ -0,0 +1,117 @@
+package rssFeed
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCleanXML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"clean", `<rss><a>x &amp; y</a></rss>`, `<rss><a>x &amp; y</a></rss>`},
+		{"bare ampersand", `<rss><a>x & y</a></rss>`, `<rss><a>x &amp; y</a></rss>`},
+		{"references kept", `<rss><a>&lt;&#38;&#x26;&nbsp;</a></rss>`, `<rss><a>&lt;&#38;&#x26;&nbsp;</a></rss>`},
+		{"ampersand in url", `<rss><link>https://a.example/?a=1&b=2</link></rss>`, `<rss><link>https://a.example/?a=1&amp;b=2</link></rss>`},
+		{"control characters", "<rss><a>x\x00\x08\x0by\t\n\r</a></rss>", "<rss><a>xy\t\n\r</a></rss>"},
+		{"junk before", "\xef\xbb\xbf \n<rss></rss>", "<rss></rss>"},
+		{"tags before declaration", `<br /><b>Notice</b><?xml version="1.0"?><rss></rss>`, `<?xml version="1.0"?><rss></rss>`},
+		{"tags before rss", `<br /><b>Notice</b><rss></rss>`, `<rss></rss>`},
+		{"junk after", "<rss></rss>\n<!-- served in 3ms --><script>x</script>", "<rss></rss>"},
+		{"cdata kept", `<rss><a><![CDATA[x & y]]> & z</a></rss>`, `<rss><a><![CDATA[x & y]]> &amp; z</a></rss>`},
+		{"control characters in cdata", "<rss><a><![CDATA[x\x01y]]></a></rss>", "<rss><a><![CDATA[xy]]></a></rss>"},
+		{"unterminated cdata", `<rss><a><![CDATA[x & y`, `<rss><a><![CDATA[x & y`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(cleanXML([]byte(tt.in))); got != tt.want {
+				t.Errorf("cleanXML(%q)\n got  %q\n want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		title   string
+		items   []string
+		warning string
+	}{
+		{
+			name:  "well-formed",
+			body:  `<rss><channel><title>Feed</title><item><title>One</title></item></channel></rss>`,
+			title: "Feed",
+			items: []string{"One"},
+		},
+		{
+			name:    "bare ampersand",
+			body:    `<rss><channel><title>Tom & Jerry</title><item><title>One</title></item></channel></rss>`,
+			title:   "Tom & Jerry",
+			items:   []string{"One"},
+			warning: "parsed leniently",
+		},
+		{
+			name:    "html entities",
+			body:    `<rss><channel><title>A&nbsp;B &mdash; C</title></channel></rss>`,
+			title:   "A B — C",
+			warning: "parsed leniently",
+		},
+		{
+			name:    "control characters",
+			body:    "<rss><channel><title>Fe\x0bed</title><item><title>One\x1f</title></item></channel></rss>",
+			title:   "Feed",
+			items:   []string{"One"},
+			warning: "parsed leniently",
+		},
+		{
+			name:    "junk before and after",
+			body:    "\n\n<br />Warning: something<rss><channel><title>Feed</title></channel></rss><!-- cache -->",
+			title:   "Feed",
+			warning: "parsed leniently",
+		},
+		{
+			name:    "items before an error are kept",
+			body:    `<rss><channel><title>Feed</title><item><title>One</title></item><item><title>Two</title></item><item><title>Three</ti`,
+			title:   "Feed",
+			items:   []string{"One", "Two"},
+			warning: "only the first 2 items could be read",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, warning, err := parseFeed([]byte(tt.body), "")
+			if err != nil {
+				t.Fatalf("parseFeed failed: %v", err)
+			}
+			if feed.Channel.Title != tt.title {
+				t.Errorf("title = %q, want %q", feed.Channel.Title, tt.title)
+			}
+			var items []string
+			for _, item := range feed.Channel.Items {
+				items = append(items, item.Title)
+			}
+			if strings.Join(items, ",") != strings.Join(tt.items, ",") {
+				t.Errorf("items = %q, want %q", items, tt.items)
+			}
+			if tt.warning == "" && warning != "" {
+				t.Errorf("warning = %q, want none", warning)
+			}
+			if !strings.Contains(warning, tt.warning) {
+				t.Errorf("warning = %q, want it to contain %q", warning, tt.warning)
+			}
+		})
+	}
+}
+
+func TestParseFeedNotAFeed(t *testing.T) {
+	for _, body := range []string{"", "not xml at all", "<html><body><p>Not found</p></body></html>"} {
+		feed, _, err := parseFeed([]byte(body), "text/html")
+		if err == nil {
+			t.Errorf("parseFeed(%q) = %+v, want an error", body, feed)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package rssFeed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// OutputFeed is a feed generated by gator itself, such as all posts a user
+// follows, which can be rendered as RSS 2.0 or Atom.
+type OutputFeed struct {
+	ID          string
+	Title       string
+	Link        string
+	Description string
+	Author      string
+	Updated     time.Time
+	Items       []OutputItem
+	// SelfURL is where the feed itself can be fetched, if anywhere.
+	SelfURL string
+}
+
+type OutputItem struct {
+	// GUID must stay the same for a post across renders so readers do not
+	// show it twice.
+	GUID        string
+	Title       string
+	Link        string
+	Description string
+	Published   time.Time
+	SourceTitle string
+	SourceURL   string
+}
+
+type rssOutput struct {
+	XMLName xml.Name         `xml:"rss"`
+	Version string           `xml:"version,attr"`
+	Channel rssOutputChannel `xml:"channel"`
+}
+
+type rssOutputChannel struct {
+	Title         string          `xml:"title"`
+	Link          string          `xml:"link"`
+	Description   string          `xml:"description"`
+	LastBuildDate string          `xml:"lastBuildDate"`
+	Generator     string          `xml:"generator"`
+	Items         []rssOutputItem `xml:"item"`
+}
+
+type rssOutputItem struct {
+	Title       string          `xml:"title"`
+	Link        string          `xml:"link"`
+	Description string          `xml:"description,omitempty"`
+	PubDate     string          `xml:"pubDate"`
+	GUID        rssOutputGUID   `xml:"guid"`
+	Source      rssOutputSource `xml:"source"`
+}
+
+type rssOutputGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssOutputSource struct {
+	URL   string `xml:"url,attr"`
+	Title string `xml:",chardata"`
+}
+
+// RSS renders the feed as an RSS 2.0 document.
+func (f OutputFeed) RSS() ([]byte, error) {
+	doc := rssOutput{
+		Version: "2.0",
+		Channel: rssOutputChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Description,
+			LastBuildDate: f.Updated.Format(time.RFC1123Z),
+			Generator:     "gator",
+		},
+	}
+	for _, item := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssOutputItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			GUID:        rssOutputGUID{Value: item.GUID},
+			Source:      rssOutputSource{URL: item.SourceURL, Title: item.SourceTitle},
+		})
+	}
+	return marshalOutput(doc)
+}
+
+type atomOutput struct {
+	XMLName   xml.Name          `xml:"http://www.w3.org/2005/Atom feed"`
+	ID        string            `xml:"id"`
+	Title     string            `xml:"title"`
+	Subtitle  string            `xml:"subtitle,omitempty"`
+	Updated   string            `xml:"updated"`
+	Author    atomOutputAuthor  `xml:"author"`
+	Generator string            `xml:"generator"`
+	Links     []atomOutputLink  `xml:"link"`
+	Entries   []atomOutputEntry `xml:"entry"`
+}
+
+type atomOutputAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomOutputLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomOutputText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomOutputEntry struct {
+	ID        string           `xml:"id"`
+	Title     string           `xml:"title"`
+	Updated   string           `xml:"updated"`
+	Published string           `xml:"published"`
+	Links     []atomOutputLink `xml:"link"`
+	Summary   *atomOutputText  `xml:"summary"`
+	Source    atomOutputSource `xml:"source"`
+}
+
+type atomOutputSource struct {
+	ID    string           `xml:"id"`
+	Title string           `xml:"title"`
+	Links []atomOutputLink `xml:"link"`
+}
+
+// Atom renders the feed as an Atom 1.0 document.
+func (f OutputFeed) Atom() ([]byte, error) {
+	doc := atomOutput{
+		ID:        f.ID,
+		Title:     f.Title,
+		Subtitle:  f.Description,
+		Updated:   f.Updated.Format(time.RFC3339),
+		Author:    atomOutputAuthor{Name: f.Author},
+		Generator: "gator",
+		Links:     []atomOutputLink{{Rel: "alternate", Href: f.Link}},
+	}
+	if f.SelfURL != "" {
+		doc.Links = append(doc.Links, atomOutputLink{Rel: "self", Href: f.SelfURL})
+	}
+	for _, item := range f.Items {
+		entry := atomOutputEntry{
+			ID:        item.GUID,
+			Title:     item.Title,
+			Updated:   item.Published.Format(time.RFC3339),
+			Published: item.Published.Format(time.RFC3339),
+			Links:     []atomOutputLink{{Rel: "alternate", Href: item.Link}},
+			Source: atomOutputSource{
+				ID:    item.SourceURL,
+				Title: item.SourceTitle,
+				Links: []atomOutputLink{{Rel: "self", Href: item.SourceURL}},
+			},
+		}
+		if item.Description != "" {
+			entry.Summary = &atomOutputText{Type: "html", Value: item.Description}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshalOutput(doc)
+}
+
+func marshalOutput(doc interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(xml.Header), data...), '\n'), nil
+}
@@ -0,0 +1,127 @@
+package rssFeed
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that only identify where a click
+// came from. They are removed so the same post shared through different
+// campaigns is stored once.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "gbraid": true, "wbraid": true,
+	"msclkid": true, "yclid": true, "igshid": true, "twclid": true, "mc_cid": true,
+	"mc_eid": true, "_hsenc": true, "_hsmi": true, "mkt_tok": true, "oly_anon_id": true,
+	"oly_enc_id": true, "vero_id": true, "vero_conv": true, "__s": true, "ref_src": true,
+}
+
+// trackingPrefixes match whole families of tracking parameters, such as
+// utm_source and utm_medium.
+var trackingPrefixes = []string{"utm_", "pk_", "mtm_", "itm_"}
+
+// NormalizeURL returns the canonical form of an absolute http(s) URL that
+// is used to recognise posts: lowercase scheme and host, no default port,
+// no fragment and no tracking parameters. Other URLs are returned trimmed
+// but otherwise unchanged.
+func NormalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return raw
+	}
+
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = u.Hostname()
+		if strings.Contains(u.Host, ":") {
+			u.Host = "[" + u.Host + "]"
+		}
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.RawQuery = stripTracking(u.RawQuery)
+	u.ForceQuery = false
+	return u.String()
+}
+
+// stripTracking removes tracking parameters from a raw query, keeping the
+// order and encoding of the rest.
+func stripTracking(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	var kept []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if isTrackingParam(strings.ToLower(key)) {
+			continue
+		}
+		kept = append(kept, param)
+	}
+	return strings.Join(kept, "&")
+}
+
+func isTrackingParam(key string) bool {
+	if trackingParams[key] {
+		return true
+	}
+	for _, prefix := range trackingPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveURL resolves ref against base. If ref can not be parsed, or there
+// is no base, it is returned as it is.
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if base == nil || ref == "" {
+		return ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+// itemLink returns the normalized absolute link of item: its link resolved
+// against base, or else its guid if that is a web address. It is empty for
+// items that have neither.
+func itemLink(base *url.URL, item RSSItem) string {
+	if link, err := url.Parse(resolveURL(base, item.Link)); err == nil && strings.TrimSpace(item.Link) != "" && link.IsAbs() {
+		return NormalizeURL(link.String())
+	}
+	// A guid that is not a URL is no link, whatever base it is resolved
+	// against, so it has to be absolute already.
+	if guid, err := url.Parse(strings.TrimSpace(item.GUID)); err == nil && (guid.Scheme == "http" || guid.Scheme == "https") && guid.Host != "" {
+		return NormalizeURL(guid.String())
+	}
+	return ""
+}
+
+// baseURL returns the URL relative links inside an element are resolved
+// against: its xml:base, itself resolved against the parent's base, or the
+// parent's base if it has none.
+func baseURL(parent *url.URL, xmlBase string) *url.URL {
+	u, err := url.Parse(strings.TrimSpace(xmlBase))
+	if err != nil || u.String() == "" {
+		return parent
+	}
+	return parent.ResolveReference(u)
+}
@@ -0,0 +1,98 @@
+package rssFeed
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://Example.COM/a", "https://example.com/a"},
+		{"HTTP://example.com:80/a", "http://example.com/a"},
+		{"https://example.com:443/a", "https://example.com/a"},
+		{"https://example.com:8443/a", "https://example.com:8443/a"},
+		{"https://example.com", "https://example.com/"},
+		{"https://example.com/a#comments", "https://example.com/a"},
+		{"https://example.com/a?utm_source=x&id=1&fbclid=y", "https://example.com/a?id=1"},
+		{"https://example.com/a?UTM_Medium=x", "https://example.com/a"},
+		{"https://example.com/a?", "https://example.com/a"},
+		{"https://example.com/a?b=1&&c=%20", "https://example.com/a?b=1&c=%20"},
+		{"http://[::1]:80/a", "http://[::1]/a"},
+		{"  https://example.com/a  ", "https://example.com/a"},
+		{"mailto:me@example.com", "mailto:me@example.com"},
+		{"/relative?utm_source=x", "/relative?utm_source=x"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeURL(tt.in); got != tt.want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/")
+	tests := []struct {
+		base *url.URL
+		ref  string
+		want string
+	}{
+		{base, "post", "https://example.com/blog/post"},
+		{base, "/post", "https://example.com/post"},
+		{base, "https://other.org/x", "https://other.org/x"},
+		{base, "", ""},
+		{nil, "post", "post"},
+		{base, "%zz", "%zz"},
+	}
+	for _, tt := range tests {
+		if got := resolveURL(tt.base, tt.ref); got != tt.want {
+			t.Errorf("resolveURL(%v, %q) = %q, want %q", tt.base, tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestItemLink(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/")
+	tests := []struct {
+		name string
+		base *url.URL
+		item RSSItem
+		want string
+	}{
+		{"absolute link", base, RSSItem{Link: "https://Example.com/a?utm_source=x"}, "https://example.com/a"},
+		{"relative link", base, RSSItem{Link: "a"}, "https://example.com/blog/a"},
+		{"link over guid", base, RSSItem{Link: "a", GUID: "https://example.com/guid"}, "https://example.com/blog/a"},
+		{"guid fallback", base, RSSItem{GUID: "https://example.com/guid#x"}, "https://example.com/guid"},
+		{"relative link without base", nil, RSSItem{Link: "a", GUID: "https://example.com/guid"}, "https://example.com/guid"},
+		{"guid that is no URL", base, RSSItem{GUID: "tag:example.com,2024:1"}, ""},
+		{"relative guid", base, RSSItem{GUID: "1234"}, ""},
+		{"nothing", base, RSSItem{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := itemLink(tt.base, tt.item); got != tt.want {
+				t.Errorf("itemLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	parent, _ := url.Parse("https://example.com/feed/")
+	tests := []struct {
+		xmlBase string
+		want    string
+	}{
+		{"", "https://example.com/feed/"},
+		{"  ", "https://example.com/feed/"},
+		{"posts/", "https://example.com/feed/posts/"},
+		{"https://other.org/", "https://other.org/"},
+	}
+	for _, tt := range tests {
+		if got := baseURL(parent, tt.xmlBase).String(); got != tt.want {
+			t.Errorf("baseURL(%q) = %q, want %q", tt.xmlBase, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	ruleFieldAny         = "any"
+	ruleFieldTitle       = "title"
+	ruleFieldDescription = "description"
+	ruleFieldURL         = "url"
+	ruleFieldFeed        = "feed"
+
+	ruleActionMute = "mute"
+	ruleActionRead = "read"
+	ruleActionStar = "star"
+	ruleActionTag  = "tag"
+
+	ruleTestDefaultLimit = 10
+)
+
+var (
+	ruleFields  = []string{ruleFieldAny, ruleFieldTitle, ruleFieldDescription, ruleFieldURL, ruleFieldFeed}
+	ruleActions = []string{ruleActionMute, ruleActionRead, ruleActionStar, ruleActionTag}
+)
+
+// rulePost is the part of a post filter rules look at.
+type rulePost struct {
+	ID          uuid.UUID
+	Title       string
+	Description string
+	URL         string
+	FeedName    string
+	FeedURL     string
+}
+
+// compiledRule is a filter rule ready to be matched. Keywords match case
+// insensitively; regular expressions are used as written.
+type compiledRule struct {
+	database.FilterRule
+	re      *regexp.Regexp
+	keyword string
+}
+
+func compileRule(rule database.FilterRule) (compiledRule, error) {
+	compiled := compiledRule{FilterRule: rule}
+	if !rule.IsRegex {
+		compiled.keyword = strings.ToLower(rule.Pattern)
+		return compiled, nil
+	}
+	re, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return compiledRule{}, fmt.Errorf("invalid regular expression %q: %w", rule.Pattern, err)
+	}
+	compiled.re = re
+	return compiled, nil
+}
+
+func (r compiledRule) matches(post rulePost) bool {
+	var values []string
+	switch r.Field {
+	case ruleFieldTitle:
+		values = []string{post.Title}
+	case ruleFieldDescription:
+		values = []string{post.Description}
+	case ruleFieldURL:
+		values = []string{post.URL}
+	case ruleFieldFeed:
+		values = []string{post.FeedName, post.FeedURL}
+	default:
+		values = []string{post.Title, post.Description, post.URL}
+	}
+
+	for _, value := range values {
+		if r.re != nil && r.re.MatchString(value) {
+			return true
+		}
+		if r.re == nil && strings.Contains(strings.ToLower(value), r.keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r compiledRule) String() string {
+	match := fmt.Sprintf("contains %q", r.Pattern)
+	if r.IsRegex {
+		match = fmt.Sprintf("matches /%s/", r.Pattern)
+	}
+	action := r.Action
+	if r.Action == ruleActionTag {
+		action = fmt.Sprintf("tag %q", r.Tag.String)
+	}
+	return fmt.Sprintf("%s if %s %s", action, r.Field, match)
+}
+
+// apply performs the rule's action on post for the rule's owner.
+func (r compiledRule) apply(ctx context.Context, db database.Querier, postID uuid.UUID) error {
+	now := time.Now().UTC()
+	switch r.Action {
+	case ruleActionMute:
+		markPostMutedParams := database.MarkPostMutedParams{
+			UserID:  r.UserID,
+			PostID:  postID,
+			MutedAt: sql.NullTime{Time: now, Valid: true},
+		}
+		return db.MarkPostMuted(ctx, markPostMutedParams)
+	case ruleActionRead:
+		markPostReadParams := database.MarkPostReadParams{
+			UserID: r.UserID,
+			PostID: postID,
+			ReadAt: sql.NullTime{Time: now, Valid: true},
+		}
+		return db.MarkPostRead(ctx, markPostReadParams)
+	case ruleActionStar:
+		markPostStarredParams := database.MarkPostStarredParams{
+			UserID:    r.UserID,
+			PostID:    postID,
+			StarredAt: sql.NullTime{Time: now, Valid: true},
+		}
+		return db.MarkPostStarred(ctx, markPostStarredParams)
+	case ruleActionTag:
+		createTagParams := database.CreateTagParams{
+			ID:        uuid.New(),
+			UserID:    r.UserID,
+			Name:      r.Tag.String,
+			CreatedAt: now,
+		}
+		tag, err := db.CreateTag(ctx, createTagParams)
+		if err != nil {
+			return err
+		}
+		tagPostParams := database.TagPostParams{
+			PostID:    postID,
+			TagID:     tag.ID,
+			CreatedAt: now,
+		}
+		return db.TagPost(ctx, tagPostParams)
+	}
+	return fmt.Errorf("unknown rule action %s", r.Action)
+}
+
+// applyFilterRules runs the rules of everyone following feed against the
+// posts just stored for it.
+func applyFilterRules(s *state, feed database.Feed, posts []database.Post, warn func(format string, args ...interface{})) {
+	if len(posts) == 0 {
+		return
+	}
+	rules, err := s.db.GetFilterRulesForFeed(context.Background(), feed.ID)
+	if err != nil {
+		warn("Error getting filter rules: %v", err)
+		return
+	}
+
+	for _, rule := range rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			warn("Error in filter rule %s: %v", rule.ID, err)
+			continue
+		}
+		for _, post := range posts {
+			target := rulePost{
+				ID:          post.ID,
+				Title:       post.Title,
+				Description: post.Description.String,
+				URL:         post.Url,
+				FeedName:    feed.Name,
+				FeedURL:     feed.Url,
+			}
+			if !compiled.matches(target) {
+				continue
+			}
+			err := compiled.apply(context.Background(), s.db, post.ID)
+			if err != nil {
+				warn("Error applying filter rule %s: %v", rule.ID, err)
+			}
+		}
+	}
+}
+
+func handlerRule(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) == 0 {
+		return errors.New("rule requires a subcommand (add, list, remove, test or apply)")
+	}
+	args := cmd.Arguments[1:]
+
+	switch cmd.Arguments[0] {
+	case "add":
+		return ruleAdd(s, args, user)
+	case "list":
+		if len(args) != 0 {
+			return fmt.Errorf("rule list dosent require any arguments, found %v arguments", args)
+		}
+		rules, err := s.db.GetFilterRulesForUser(context.Background(), user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get filter rules: %w", err)
+		}
+		for _, rule := range rules {
+			fmt.Printf("%s  %s\n", rule.ID, compiledRule{FilterRule: rule})
+		}
+	case "remove":
+		if len(args) != 1 {
+			return fmt.Errorf("rule remove requires exactly 1 argument (rule id), found %v arguments", len(args))
+		}
+		id, err := uuid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid rule id: %s", args[0])
+		}
+		deleteFilterRuleParams := database.DeleteFilterRuleParams{
+			ID:     id,
+			UserID: user.ID,
+		}
+		deleted, err := s.db.DeleteFilterRule(context.Background(), deleteFilterRuleParams)
+		if err != nil {
+			return fmt.Errorf("failed to remove filter rule: %w", err)
+		}
+		if deleted == 0 {
+			return errors.New("the filter rule dose not exists")
+		}
+		fmt.Println("The filter rule has been removed. Posts it already changed stay as they are.")
+	case "test":
+		return ruleTest(s, args, user)
+	case "apply":
+		return ruleApply(s, args, user)
+	default:
+		return fmt.Errorf("unknown rule subcommand %v, expected add, list, remove, test or apply", cmd.Arguments[0])
+	}
+
+	return nil
+}
+
+func ruleAdd(s *state, args []string, user database.User) error {
+	args, field, err := popFlagValue(args, "--in")
+	if err != nil {
+		return err
+	}
+	args, tag, err := popFlagValue(args, "--tag")
+	if err != nil {
+		return err
+	}
+	args, isRegex := popFlag(args, "--regex")
+	if len(args) != 2 {
+		return fmt.Errorf("rule add requires exactly 2 arguments (action and pattern), found %v arguments", len(args))
+	}
+
+	if field == "" {
+		field = ruleFieldAny
+	}
+	if !slices.Contains(ruleFields, field) {
+		return fmt.Errorf("unknown field %s, expected one of %s", field, strings.Join(ruleFields, ", "))
+	}
+	action := args[0]
+	if !slices.Contains(ruleActions, action) {
+		return fmt.Errorf("unknown action %s, expected one of %s", action, strings.Join(ruleActions, ", "))
+	}
+	if action == ruleActionTag && tag == "" {
+		return errors.New("the tag action requires --tag <name>")
+	}
+	if tag != "" {
+		err := validateTagName(tag)
+		if err != nil {
+			return err
+		}
+	}
+	if action != ruleActionTag && tag != "" {
+		return errors.New("--tag can only be used with the tag action")
+	}
+	if args[1] == "" {
+		return errors.New("the pattern can not be empty")
+	}
+
+	createFilterRuleParams := database.CreateFilterRuleParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Field:     field,
+		Pattern:   args[1],
+		IsRegex:   isRegex,
+		Action:    action,
+		Tag:       sql.NullString{String: tag, Valid: tag != ""},
+		CreatedAt: time.Now().UTC(),
+	}
+	_, err = compileRule(database.FilterRule(createFilterRuleParams))
+	if err != nil {
+		return err
+	}
+
+	rule, err := s.db.CreateFilterRule(context.Background(), createFilterRuleParams)
+	if err != nil {
+		return fmt.Errorf("failed to create filter rule: %w", err)
+	}
+	fmt.Printf("Created rule %s: %s\n", rule.ID, compiledRule{FilterRule: rule})
+	fmt.Println("It applies to new posts; run \"rule apply\" to apply it to stored posts too.")
+	return nil
+}
+
+// userRules returns the rule with the given id, or all of the user's rules
+// if id is empty.
+func userRules(s *state, user database.User, id string) ([]compiledRule, error) {
+	var rules []database.FilterRule
+	if id == "" {
+		var err error
+		rules, err = s.db.GetFilterRulesForUser(context.Background(), user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get filter rules: %w", err)
+		}
+	} else {
+		ruleID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule id: %s", id)
+		}
+		getFilterRuleParams := database.GetFilterRuleParams{
+			ID:     ruleID,
+			UserID: user.ID,
+		}
+		rule, err := s.db.GetFilterRule(context.Background(), getFilterRuleParams)
+		if err == sql.ErrNoRows {
+			return nil, errors.New("the filter rule dose not exists")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get filter rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	var compiled []compiledRule
+	for _, rule := range rules {
+		c, err := compileRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+func followedRulePosts(ctx context.Context, db database.Querier, user database.User) ([]rulePost, error) {
+	posts, err := db.GetFollowedPosts(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch posts: %w", err)
+	}
+	targets := make([]rulePost, 0, len(posts))
+	for _, post := range posts {
+		targets = append(targets, rulePost{
+			ID:          post.ID,
+			Title:       post.Title,
+			Description: post.Description.String,
+			URL:         post.Url,
+			FeedName:    post.FeedName,
+			FeedURL:     post.FeedUrl,
+		})
+	}
+	return targets, nil
+}
+
+// ruleTest shows which stored posts the rules match without changing them.
+func ruleTest(s *state, args []string, user database.User) error {
+	args, limitValue, err := popFlagValue(args, "--limit")
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("rule test takes at most 1 argument (rule id), found %v arguments", len(args))
+	}
+	limit := ruleTestDefaultLimit
+	if limitValue != "" {
+		limit, err = strconv.Atoi(limitValue)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("invalid limit: %s", limitValue)
+		}
+	}
+	id := ""
+	if len(args) > 0 {
+		id = args[0]
+	}
+
+	rules, err := userRules(s, user, id)
+	if err != nil {
+		return err
+	}
+	posts, err := followedRulePosts(context.Background(), s.db, user)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		var matched []rulePost
+		for _, post := range posts {
+			if rule.matches(post) {
+				matched = append(matched, post)
+			}
+		}
+		fmt.Printf("%s  %s: %v of %v posts match\n", rule.ID, rule, len(matched), len(posts))
+		for i, post := range matched {
+			if i == limit {
+				fmt.Printf("    ... and %v more\n", len(matched)-limit)
+				break
+			}
+			fmt.Printf("    %s (%s)\n", post.Title, post.FeedName)
+		}
+	}
+	return nil
+}
+
+// ruleApply runs the rules against every stored post of the followed feeds.
+func ruleApply(s *state, args []string, user database.User) error {
+	if len(args) > 1 {
+		return fmt.Errorf("rule apply takes at most 1 argument (rule id), found %v arguments", len(args))
+	}
+	id := ""
+	if len(args) > 0 {
+		id = args[0]
+	}
+
+	rules, err := userRules(s, user, id)
+	if err != nil {
+		return err
+	}
+
+	applied := 0
+	err = s.withTx(context.Background(), func(db database.Querier) error {
+		posts, err := followedRulePosts(context.Background(), db, user)
+		if err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			for _, post := range posts {
+				if !rule.matches(post) {
+					continue
+				}
+				err := rule.apply(context.Background(), db, post.ID)
+				if err != nil {
+					return fmt.Errorf("failed to apply rule %s: %w", rule.ID, err)
+				}
+				applied++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Applied %v rules to stored posts, %v matches\n", len(rules), applied)
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+)
+
+type server struct {
+	state *state
+}
+
+func handlerServe(s *state, cmd command) error {
+	args, addr, err := popFlagValue(cmd.Arguments, "--addr")
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("serve only accepts --addr, found %v arguments", args)
+	}
+	if addr == "" {
+		addr = ":8080"
+	}
+
+	srv := &server{state: s}
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           srv.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Serving on %s\n", addr)
+	err = httpServer.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+func (srv *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	srv.registerAPI(mux)
+	srv.registerGReader(mux)
+	srv.registerUI(mux)
+	return mux
+}
+
+// authenticated resolves the bearer API token of the request to a user.
+func (srv *server) authenticated(handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || token == "" {
+			respondWithError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		user, err := srv.userForAPIToken(r.Context(), token)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		handler(w, r, user)
+	}
+}
+
+// feedAuthenticated is like authenticated, but for URLs polled by feed
+// readers, which can not set headers. Those may pass the API token as the
+// token query parameter instead.
+func (srv *server) feedAuthenticated(handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	authenticated := srv.authenticated(handler)
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		authenticated(w, r)
+	}
+}
+
+func (srv *server) userForAPIToken(ctx context.Context, token string) (database.User, error) {
+	tokenHash := hashToken(token)
+	user, err := srv.state.db.GetUserByAPIToken(ctx, tokenHash)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	touchAPITokenParams := database.TouchAPITokenParams{
+		TokenHash:  tokenHash,
+		LastUsedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	}
+	err = srv.state.db.TouchAPIToken(ctx, touchAPITokenParams)
+	if err != nil {
+		log.Printf("failed to update api token usage: %v", err)
+	}
+	return user, nil
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("failed to marshal JSON response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+func respondWithError(w http.ResponseWriter, code int, msg string) {
+	if code >= 500 {
+		log.Printf("responding with %d: %s", code, msg)
+	}
+	type errorResponse struct {
+		Error string `json:"error"`
+	}
+	respondWithJSON(w, code, errorResponse{Error: msg})
+}
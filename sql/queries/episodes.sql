@@ -0,0 +1,58 @@
+-- name: CreateEpisode :exec
+INSERT INTO episodes (post_id, media_url, media_type, media_length, duration_seconds, episode_number, season_number, image_url, explicit, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (post_id) DO NOTHING;
+
+-- name: GetPodcastsForUser :many
+-- The followed feeds that have at least one episode.
+SELECT
+    feeds.id AS feed_id,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    COUNT(episodes.post_id) AS episode_count,
+    COUNT(post_states.played_at) AS played_count,
+    COUNT(post_states.downloaded_at) AS downloaded_count,
+    MAX(posts.created_at)::timestamptz AS newest_created_at
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+JOIN posts ON posts.feed_id = feeds.id
+JOIN episodes ON episodes.post_id = posts.id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+AND post_states.muted_at IS NULL
+GROUP BY feeds.id, feeds.name, feeds.url
+ORDER BY feeds.name;
+
+-- name: GetEpisodesForUser :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    episodes.media_url,
+    episodes.media_type,
+    episodes.media_length,
+    episodes.duration_seconds,
+    episodes.episode_number,
+    episodes.season_number,
+    episodes.image_url,
+    episodes.explicit,
+    post_states.played_at,
+    post_states.downloaded_at,
+    post_states.download_path
+FROM posts
+JOIN episodes ON episodes.post_id = posts.id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = @user_id
+AND post_states.muted_at IS NULL
+AND (sqlc.narg('post_id')::uuid IS NULL OR posts.id = sqlc.narg('post_id')::uuid)
+AND (sqlc.narg('feed_url')::text IS NULL OR feeds.url = sqlc.narg('feed_url')::text)
+AND (NOT @unplayed_only::boolean OR post_states.played_at IS NULL)
+AND (NOT @downloaded_only::boolean OR post_states.downloaded_at IS NOT NULL)
+AND (NOT @not_downloaded_only::boolean OR post_states.downloaded_at IS NULL)
+ORDER BY posts.published_at DESC
+LIMIT sqlc.arg('limit');
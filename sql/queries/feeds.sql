@@ -9,10 +9,48 @@ SELECT * FROM feeds;
 -- name: GetFeedByURL :one
 SELECT * FROM feeds WHERE url = $1 LIMIT 1;
 
+-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1;
+
+-- name: DeleteAllFeeds :exec
+DELETE FROM feeds;
+
+-- name: TransferFeed :one
+UPDATE feeds
+SET user_id = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: SetFeedFetchFullContent :one
+UPDATE feeds
+SET fetch_full_content = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: ReassignFeedsOfUser :exec
+-- Hands every feed the user added to the longest-standing other follower.
+-- Feeds nobody else follows keep their owner until the user is deleted,
+-- at which point they fall back to the system (NULL owner).
+UPDATE feeds
+SET user_id = (
+        SELECT feed_follows.user_id FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id
+        AND feed_follows.user_id <> @user_id
+        ORDER BY feed_follows.created_at
+        LIMIT 1
+    ),
+    updated_at = @updated_at
+WHERE feeds.user_id = @user_id
+AND EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id
+    AND feed_follows.user_id <> @user_id
+);
+
 -- name: MarkFeedFetched :exec
 UPDATE feeds
-SET last_fetched_at = now(), updated_at = now()
-WHERE id = $1;
+SET last_fetched_at = @fetched_at::timestamptz, updated_at = @fetched_at::timestamptz
+WHERE id = @id;
 
 -- name: GetNextFeedToFetch :one
 SELECT * FROM feeds
@@ -22,4 +60,8 @@ WHERE last_fetched_at IS NULL
        FROM feeds
    )
 ORDER BY last_fetched_at NULLS FIRST
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+-- name: SetFeedParseWarning :exec
+UPDATE feeds
+SET last_parse_warning = $2
+WHERE id = $1;
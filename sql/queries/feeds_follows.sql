@@ -16,15 +16,30 @@ INNER JOIN users ON users.id = inserted_feed_follow.user_id;
 SELECT
     feed_follows.*,
     feeds.name AS feed_name,
+    feeds.url AS feed_url,
     users.name AS user_name
 FROM feed_follows
 INNER JOIN feeds ON feed_follows.feed_id = feeds.id
 INNER JOIN users ON feed_follows.user_id = users.id
-WHERE feed_follows.user_id = $1;
+WHERE feed_follows.user_id = $1
+ORDER BY feed_follows.folder, feeds.name;
 
 -- name: DeleteFeedFollowByUserAndFeedURL :exec
 DELETE FROM feed_follows
 USING feeds
 WHERE feed_follows.feed_id = feeds.id
 AND feed_follows.user_id = $1
+AND feeds.url = $2;
+
+-- name: GetFeedFollow :one
+SELECT * FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2;
+
+
+-- name: SetFeedFollowFolder :execrows
+UPDATE feed_follows
+SET folder = $3, updated_at = $4
+FROM feeds
+WHERE feed_follows.feed_id = feeds.id
+AND feed_follows.user_id = $1
 AND feeds.url = $2;
\ No newline at end of file
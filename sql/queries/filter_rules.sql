@@ -0,0 +1,24 @@
+-- name: CreateFilterRule :one
+INSERT INTO filter_rules (id, user_id, field, pattern, is_regex, action, tag, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetFilterRulesForUser :many
+SELECT * FROM filter_rules
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetFilterRule :one
+SELECT * FROM filter_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteFilterRule :execrows
+DELETE FROM filter_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetFilterRulesForFeed :many
+-- The rules of every user following the feed.
+SELECT filter_rules.* FROM filter_rules
+JOIN feed_follows ON feed_follows.user_id = filter_rules.user_id
+WHERE feed_follows.feed_id = $1
+ORDER BY filter_rules.user_id, filter_rules.created_at;
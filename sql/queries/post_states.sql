@@ -0,0 +1,45 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_states (user_id, post_id, read_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET read_at = EXCLUDED.read_at;
+
+-- name: MarkPostStarred :exec
+INSERT INTO post_states (user_id, post_id, starred_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET starred_at = EXCLUDED.starred_at;
+
+-- name: MarkPostMuted :exec
+INSERT INTO post_states (user_id, post_id, muted_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET muted_at = EXCLUDED.muted_at;
+
+-- name: IsPostMuted :one
+SELECT EXISTS (
+    SELECT 1 FROM post_states
+    WHERE user_id = $1 AND post_id = $2 AND muted_at IS NOT NULL
+);
+
+
+-- name: MarkAllPostsRead :exec
+-- Marks every followed post created up to older_than as read, optionally
+-- only for one feed or folder. Posts that are already read keep their
+-- read time.
+INSERT INTO post_states (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, @read_at::timestamptz
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = @user_id
+AND (sqlc.narg('feed_url')::text IS NULL OR feeds.url = sqlc.narg('feed_url')::text)
+AND (sqlc.narg('folder')::text IS NULL OR feed_follows.folder = sqlc.narg('folder')::text)
+AND posts.created_at <= @older_than::timestamptz
+ON CONFLICT (user_id, post_id) DO UPDATE SET read_at = COALESCE(post_states.read_at, EXCLUDED.read_at);
+-- name: MarkPostPlayed :exec
+INSERT INTO post_states (user_id, post_id, played_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET played_at = EXCLUDED.played_at;
+
+-- name: MarkPostDownloaded :exec
+INSERT INTO post_states (user_id, post_id, downloaded_at, download_path)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, post_id) DO UPDATE SET downloaded_at = EXCLUDED.downloaded_at, download_path = EXCLUDED.download_path;
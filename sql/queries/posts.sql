@@ -1,13 +1,139 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content, author, categories)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 ON CONFLICT (url) DO NOTHING
 RETURNING *;
 
+-- name: GetPostByURL :one
+SELECT * FROM posts WHERE url = $1;
+
+-- name: GetPostById :one
+SELECT * FROM posts WHERE id = $1;
+
+-- name: CreatePostEnclosure :exec
+INSERT INTO post_enclosures (id, post_id, url, media_type, length, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (post_id, url) DO NOTHING;
+
+-- name: GetPostEnclosures :many
+SELECT * FROM post_enclosures
+WHERE post_id = $1
+ORDER BY created_at;
+
+-- name: SetPostArticle :exec
+UPDATE posts
+SET article_html = $2, article_text = $3, article_fetched_at = $4
+WHERE id = $1;
+
 -- name: GetPostsForUser :many
-SELECT posts.*
+SELECT
+    posts.*,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    post_states.read_at,
+    post_states.starred_at,
+    COALESCE((
+        SELECT string_agg(tags.name, ',' ORDER BY tags.name)
+        FROM post_tags
+        JOIN tags ON tags.id = post_tags.tag_id
+        WHERE post_tags.post_id = posts.id AND tags.user_id = feed_follows.user_id
+    ), '')::text AS tags
 FROM posts
 JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
-WHERE feed_follows.user_id = $1
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = @user_id
+AND (NOT @unread_only::boolean OR post_states.read_at IS NULL)
+AND (NOT @starred_only::boolean OR post_states.starred_at IS NOT NULL)
+AND (sqlc.narg('feed_url')::text IS NULL OR feeds.url = sqlc.narg('feed_url')::text)
+AND (sqlc.narg('folder')::text IS NULL OR feed_follows.folder = sqlc.narg('folder')::text)
+AND (post_states.muted_at IS NOT NULL) = @muted_only::boolean
+AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_tags
+    JOIN tags ON tags.id = post_tags.tag_id
+    WHERE post_tags.post_id = posts.id
+    AND tags.user_id = feed_follows.user_id
+    AND tags.name = sqlc.narg('tag')::text
+))
+-- query is a LIKE pattern, so callers escape %, _ and \ in what the user typed.
+AND (sqlc.narg('query')::text IS NULL
+    OR posts.title ILIKE '%' || sqlc.narg('query')::text || '%' ESCAPE '\'
+    OR posts.description ILIKE '%' || sqlc.narg('query')::text || '%' ESCAPE '\'
+    OR posts.content ILIKE '%' || sqlc.narg('query')::text || '%' ESCAPE '\'
+    OR posts.article_text ILIKE '%' || sqlc.narg('query')::text || '%' ESCAPE '\')
 ORDER BY posts.published_at DESC
-LIMIT $2;
\ No newline at end of file
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetPostForUserByIDRange :one
+-- Looks up a post of a followed feed whose id lies between min_id and
+-- max_id. The Google Reader API refers to items by the first 8 bytes of
+-- their id, which gives such a range.
+SELECT
+    posts.*,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    post_states.read_at,
+    post_states.starred_at,
+    COALESCE((
+        SELECT string_agg(tags.name, ',' ORDER BY tags.name)
+        FROM post_tags
+        JOIN tags ON tags.id = post_tags.tag_id
+        WHERE post_tags.post_id = posts.id AND tags.user_id = @user_id
+    ), '')::text AS tags
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = @user_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = @user_id
+WHERE posts.id BETWEEN @min_id::uuid AND @max_id::uuid
+ORDER BY posts.id
+LIMIT 1;
+
+-- name: GetUnreadCountsForUser :many
+SELECT
+    feeds.id AS feed_id,
+    feeds.url AS feed_url,
+    feed_follows.folder,
+    COUNT(posts.id) AS unread_count,
+    MAX(posts.created_at)::timestamptz AS newest_created_at
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+JOIN posts ON posts.feed_id = feeds.id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+AND post_states.read_at IS NULL
+AND post_states.muted_at IS NULL
+GROUP BY feeds.id, feeds.url, feed_follows.folder;
+-- name: GetPostsForDigest :many
+-- Unread posts from the feeds a user follows that arrived in (since, until],
+-- oldest first, so a digest cut short by the limit can continue where it
+-- stopped. Posts that arrived at since itself are included when their id
+-- comes after since_id, the last post of the digest before.
+SELECT
+    posts.*,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = @user_id
+AND (posts.created_at > @since OR (posts.created_at = @since AND posts.id > sqlc.narg('since_id')::uuid))
+AND posts.created_at <= @until
+AND post_states.read_at IS NULL
+AND post_states.muted_at IS NULL
+ORDER BY posts.created_at, posts.id
+LIMIT sqlc.arg('limit');
+
+-- name: GetFollowedPosts :many
+-- Every stored post from the feeds a user follows, for applying filter rules
+-- retroactively.
+SELECT
+    posts.*,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY posts.created_at DESC;
@@ -0,0 +1,14 @@
+-- name: CreateSession :one
+INSERT INTO sessions (token_hash, user_id, created_at, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetUserBySession :one
+SELECT users.*
+FROM sessions
+JOIN users ON users.id = sessions.user_id
+WHERE sessions.token_hash = $1
+AND sessions.expires_at > @now::timestamptz;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions WHERE token_hash = $1;
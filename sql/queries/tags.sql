@@ -0,0 +1,31 @@
+-- name: CreateTag :one
+-- Returns the user's tag with this name, creating it if needed.
+INSERT INTO tags (id, user_id, name, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: TagPost :exec
+INSERT INTO post_tags (post_id, tag_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (post_id, tag_id) DO NOTHING;
+
+-- name: GetTagByName :one
+SELECT * FROM tags
+WHERE user_id = $1 AND name = $2;
+
+-- name: UntagPost :execrows
+DELETE FROM post_tags
+WHERE post_id = $1 AND tag_id = $2;
+
+-- name: GetTagsForUser :many
+SELECT tags.*, COUNT(post_tags.post_id) AS post_count
+FROM tags
+LEFT JOIN post_tags ON post_tags.tag_id = tags.id
+WHERE tags.user_id = $1
+GROUP BY tags.id
+ORDER BY tags.name;
+
+-- name: DeleteTag :execrows
+DELETE FROM tags
+WHERE user_id = $1 AND name = $2;
@@ -1,10 +1,13 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+-- The first user to register becomes the admin.
+INSERT INTO users (id, created_at, updated_at, name, hashed_password, is_admin)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5,
+    NOT EXISTS (SELECT 1 FROM users)
 )
 RETURNING *;
 
@@ -18,4 +21,61 @@ SELECT * FROM users WHERE id = $1;
 SELECT * FROM users;
 
 -- name: DeleteAllUsers :exec
-DELETE FROM users;
\ No newline at end of file
+DELETE FROM users;
+
+-- name: UpdateUserTimezone :one
+UPDATE users
+SET timezone = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: SetUserAdmin :one
+UPDATE users
+SET is_admin = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: CountOtherAdmins :one
+SELECT COUNT(*) FROM users WHERE is_admin AND id <> $1;
+
+-- name: RenameUser :one
+UPDATE users
+SET name = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteUser :exec
+DELETE FROM users WHERE id = $1;
+
+-- name: GetUserStats :one
+SELECT
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = @user_id) AS follow_count,
+    (SELECT COUNT(*)
+     FROM posts
+     JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+     LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+     WHERE feed_follows.user_id = @user_id
+     AND post_states.read_at IS NULL
+     AND post_states.muted_at IS NULL) AS unread_count;
+
+-- name: UpdateUserEmail :one
+UPDATE users
+SET email = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUsersWithEmail :many
+SELECT * FROM users
+WHERE email IS NOT NULL
+ORDER BY name;
+
+-- name: SetUserLastDigest :exec
+UPDATE users
+SET last_digest_at = $2, last_digest_post_id = $3
+WHERE id = $1;
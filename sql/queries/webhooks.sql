@@ -0,0 +1,51 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, user_id, url, secret, feed_id, keyword, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetWebhooksForUser :many
+SELECT webhooks.*, feeds.url AS feed_url
+FROM webhooks
+LEFT JOIN feeds ON feeds.id = webhooks.feed_id
+WHERE webhooks.user_id = $1
+ORDER BY webhooks.created_at;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetWebhooksForFeed :many
+-- Webhooks limited to the feed or to no feed at all, as long as their owner
+-- follows the feed.
+SELECT * FROM webhooks
+WHERE (webhooks.feed_id IS NULL OR webhooks.feed_id = @feed_id::uuid)
+AND EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.user_id = webhooks.user_id
+    AND feed_follows.feed_id = @feed_id::uuid
+);
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, webhook_id, post_id, attempt, status_code, error, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: GetWebhookDeliveries :many
+SELECT
+    webhook_deliveries.*,
+    webhooks.url AS webhook_url,
+    posts.title AS post_title
+FROM webhook_deliveries
+JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+LEFT JOIN posts ON posts.id = webhook_deliveries.post_id
+WHERE webhooks.user_id = @user_id
+AND (sqlc.narg('webhook_id')::uuid IS NULL OR webhooks.id = sqlc.narg('webhook_id')::uuid)
+ORDER BY webhook_deliveries.created_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteWebhookDeliveriesBefore :execrows
+DELETE FROM webhook_deliveries
+WHERE created_at < $1;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Feeds outlive the user who added them; a NULL owner means the feed
+-- belongs to the system.
+ALTER TABLE feeds
+    ALTER COLUMN user_id DROP NOT NULL,
+    DROP CONSTRAINT feeds_user_id_fkey,
+    ADD CONSTRAINT feeds_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- +goose Down
+DELETE FROM feeds WHERE user_id IS NULL;
+
+ALTER TABLE feeds
+    ALTER COLUMN user_id SET NOT NULL,
+    DROP CONSTRAINT feeds_user_id_fkey,
+    ADD CONSTRAINT feeds_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,37 @@
+-- +goose Up
+ALTER TABLE post_states
+ADD COLUMN muted_at TIMESTAMPTZ NULL;
+
+CREATE TABLE tags (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE post_tags (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE TABLE filter_rules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    field TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    is_regex BOOLEAN NOT NULL DEFAULT false,
+    action TEXT NOT NULL,
+    tag TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- +goose Down
+DROP TABLE filter_rules;
+DROP TABLE post_tags;
+DROP TABLE tags;
+
+ALTER TABLE post_states
+DROP COLUMN muted_at;
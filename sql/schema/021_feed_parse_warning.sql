@@ -0,0 +1,8 @@
+-- +goose Up
+-- Set when the last fetch of a feed only parsed after its XML was repaired.
+ALTER TABLE feeds
+ADD COLUMN last_parse_warning TEXT NULL;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN last_parse_warning;
@@ -0,0 +1,8 @@
+package schema
+
+import "embed"
+
+// FS holds the goose migrations so they ship inside the gator binary.
+//
+//go:embed *.sql
+var FS embed.FS
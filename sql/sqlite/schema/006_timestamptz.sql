@@ -0,0 +1,7 @@
+-- +goose Up
+-- SQLite has no timestamp types; gator already writes UTC times with their
+-- offset, so there is nothing to convert. Kept to match sql/schema.
+SELECT 1;
+
+-- +goose Down
+SELECT 1;
@@ -0,0 +1,56 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- SQLite cannot alter a foreign key, so the table is rebuilt. Foreign keys
+-- are switched off meanwhile so dropping the old table does not cascade.
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+CREATE TABLE feeds_new (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    user_id TEXT NULL REFERENCES users(id) ON DELETE SET NULL,
+    last_fetched_at TIMESTAMP NULL
+);
+
+INSERT INTO feeds_new (id, created_at, updated_at, name, url, user_id, last_fetched_at)
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds;
+
+DROP TABLE feeds;
+
+ALTER TABLE feeds_new RENAME TO feeds;
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
+
+-- +goose Down
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+DELETE FROM feeds WHERE user_id IS NULL;
+
+CREATE TABLE feeds_new (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_fetched_at TIMESTAMP NULL
+);
+
+INSERT INTO feeds_new (id, created_at, updated_at, name, url, user_id, last_fetched_at)
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds;
+
+DROP TABLE feeds;
+
+ALTER TABLE feeds_new RENAME TO feeds;
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
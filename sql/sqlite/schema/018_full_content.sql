@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN fetch_full_content BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE posts
+ADD COLUMN article_html TEXT NULL;
+
+ALTER TABLE posts
+ADD COLUMN article_text TEXT NULL;
+
+ALTER TABLE posts
+ADD COLUMN article_fetched_at TIMESTAMP NULL;
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN article_fetched_at;
+
+ALTER TABLE posts
+DROP COLUMN article_text;
+
+ALTER TABLE posts
+DROP COLUMN article_html;
+
+ALTER TABLE feeds
+DROP COLUMN fetch_full_content;
@@ -0,0 +1,32 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN content TEXT NULL;
+
+ALTER TABLE posts
+ADD COLUMN author TEXT NULL;
+
+-- One category per line, in feed order.
+ALTER TABLE posts
+ADD COLUMN categories TEXT NULL;
+
+CREATE TABLE post_enclosures (
+    id TEXT PRIMARY KEY,
+    post_id TEXT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    media_type TEXT NOT NULL,
+    length INTEGER NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (post_id, url)
+);
+
+-- +goose Down
+DROP TABLE post_enclosures;
+
+ALTER TABLE posts
+DROP COLUMN categories;
+
+ALTER TABLE posts
+DROP COLUMN author;
+
+ALTER TABLE posts
+DROP COLUMN content;
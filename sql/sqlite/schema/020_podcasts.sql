@@ -0,0 +1,36 @@
+-- +goose Up
+-- One row per post that carries a media enclosure. media_url is the
+-- enclosure gator downloads; the rest comes from the itunes namespace.
+CREATE TABLE episodes (
+    post_id TEXT PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    media_url TEXT NOT NULL,
+    media_type TEXT NOT NULL,
+    media_length INTEGER NULL,
+    duration_seconds INTEGER NULL,
+    episode_number INTEGER NULL,
+    season_number INTEGER NULL,
+    image_url TEXT NULL,
+    explicit BOOLEAN NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE post_states
+ADD COLUMN played_at TIMESTAMP NULL;
+
+ALTER TABLE post_states
+ADD COLUMN downloaded_at TIMESTAMP NULL;
+
+ALTER TABLE post_states
+ADD COLUMN download_path TEXT NULL;
+
+-- +goose Down
+ALTER TABLE post_states
+DROP COLUMN download_path;
+
+ALTER TABLE post_states
+DROP COLUMN downloaded_at;
+
+ALTER TABLE post_states
+DROP COLUMN played_at;
+
+DROP TABLE episodes;
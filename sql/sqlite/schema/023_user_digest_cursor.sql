@@ -0,0 +1,10 @@
+-- +goose Up
+-- The last post of a digest cut short by the post limit, so the next one
+-- continues after it even when more posts arrived at the same moment. Not a
+-- foreign key, the position stays valid if the post is deleted.
+ALTER TABLE users
+ADD COLUMN last_digest_post_id TEXT NULL;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN last_digest_post_id;
@@ -0,0 +1,8 @@
+package schema
+
+import "embed"
+
+// FS holds the SQLite flavour of the goose migrations in sql/schema.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -1,15 +1,41 @@
 package main
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/Romasav/gator/internal/config"
 	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/internal/storage"
 )
 
 type state struct {
-	db     *database.Queries
-	config *config.Config
+	storage *storage.Storage
+	db      database.Querier
+	config  *config.Config
+}
+
+func newState(storage *storage.Storage, config *config.Config) *state {
+	return &state{storage, storage.Queries(), config}
 }
 
-func newState(db *database.Queries, config *config.Config) *state {
-	return &state{db, config}
+// withTx runs fn in a single database transaction and commits it if fn
+// returns nil. fn must use the Querier it is given rather than s.db.
+func (s *state) withTx(ctx context.Context, fn func(db database.Querier) error) error {
+	tx, err := s.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = fn(s.storage.WithTx(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
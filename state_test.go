@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Romasav/gator/internal/config"
+	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/internal/storage"
+	"github.com/google/uuid"
+)
+
+// newTestState returns a state backed by a fresh, fully migrated SQLite
+// database.
+func newTestState(t *testing.T) *state {
+	t.Helper()
+	dbURL := "sqlite://" + filepath.Join(t.TempDir(), "gator.db")
+	store, err := storage.Open(dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	provider, err := store.MigrationProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return newState(store, &config.Config{DbUrl: dbURL})
+}
+
+func createTestUser(t *testing.T, s *state, name string) database.User {
+	t.Helper()
+	createUserParams := database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Name:      name,
+	}
+	user, err := s.db.CreateUser(context.Background(), createUserParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// createTestFeed adds a feed owned by user, who follows it.
+func createTestFeed(t *testing.T, s *state, user database.User, url string) database.Feed {
+	t.Helper()
+	createFeedParams := database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Name:      url,
+		Url:       url,
+		UserID:    uuid.NullUUID{UUID: user.ID, Valid: true},
+	}
+	feed, err := s.db.CreateFeed(context.Background(), createFeedParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	followTestFeed(t, s, user, feed)
+	return feed
+}
+
+func followTestFeed(t *testing.T, s *state, user database.User, feed database.Feed) {
+	t.Helper()
+	createFeedFollowParams := database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	}
+	_, err := s.db.CreateFeedFollow(context.Background(), createFeedFollowParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func createTestPost(t *testing.T, s *state, feed database.Feed, url string, createdAt time.Time) database.Post {
+	t.Helper()
+	createPostParams := database.CreatePostParams{
+		ID:        uuid.New(),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Title:     url,
+		Url:       url,
+		FeedID:    feed.ID,
+	}
+	post, err := s.db.CreatePost(context.Background(), createPostParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return post
+}
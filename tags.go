@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const searchDefaultLimit = 10
+
+// postTags splits the comma separated tags column of a post row.
+func postTags(post database.GetPostsForUserRow) []string {
+	if post.Tags == "" {
+		return []string{}
+	}
+	return strings.Split(post.Tags, ",")
+}
+
+// validateTagName rejects names that would not survive the comma separated
+// tags column.
+func validateTagName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("the tag name can not be empty")
+	}
+	if strings.Contains(name, ",") {
+		return fmt.Errorf("the tag name %q can not contain commas", name)
+	}
+	return nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func handlerTag(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) < 2 {
+		return fmt.Errorf("tag requires at least 2 arguments (tag name and post URL), found %v arguments", len(cmd.Arguments))
+	}
+	name := cmd.Arguments[0]
+	err := validateTagName(name)
+	if err != nil {
+		return err
+	}
+
+	createTagParams := database.CreateTagParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+	}
+	tag, err := s.db.CreateTag(context.Background(), createTagParams)
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return forEachPostURL(s, cmd.Name, cmd.Arguments[1:], func(post database.Post) error {
+		tagPostParams := database.TagPostParams{
+			PostID:    post.ID,
+			TagID:     tag.ID,
+			CreatedAt: time.Now().UTC(),
+		}
+		err := s.db.TagPost(context.Background(), tagPostParams)
+		if err != nil {
+			return fmt.Errorf("failed to tag post: %w", err)
+		}
+		fmt.Printf("Tagged '%s' with %s\n", post.Title, tag.Name)
+		return nil
+	})
+}
+
+func handlerUntag(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) < 2 {
+		return fmt.Errorf("untag requires at least 2 arguments (tag name and post URL), found %v arguments", len(cmd.Arguments))
+	}
+
+	getTagByNameParams := database.GetTagByNameParams{
+		UserID: user.ID,
+		Name:   cmd.Arguments[0],
+	}
+	tag, err := s.db.GetTagByName(context.Background(), getTagByNameParams)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("the tag %s dose not exists", cmd.Arguments[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	return forEachPostURL(s, cmd.Name, cmd.Arguments[1:], func(post database.Post) error {
+		untagPostParams := database.UntagPostParams{
+			PostID: post.ID,
+			TagID:  tag.ID,
+		}
+		removed, err := s.db.UntagPost(context.Background(), untagPostParams)
+		if err != nil {
+			return fmt.Errorf("failed to untag post: %w", err)
+		}
+		if removed == 0 {
+			fmt.Printf("'%s' is not tagged with %s\n", post.Title, tag.Name)
+			return nil
+		}
+		fmt.Printf("Removed %s from '%s'\n", tag.Name, post.Title)
+		return nil
+	})
+}
+
+func handlerTags(s *state, cmd command, user database.User) error {
+	args, deleteName, err := popFlagValue(cmd.Arguments, "--delete")
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("tags dosent require any arguments, found %v arguments", args)
+	}
+
+	if deleteName != "" {
+		deleteTagParams := database.DeleteTagParams{
+			UserID: user.ID,
+			Name:   deleteName,
+		}
+		deleted, err := s.db.DeleteTag(context.Background(), deleteTagParams)
+		if err != nil {
+			return fmt.Errorf("failed to delete tag: %w", err)
+		}
+		if deleted == 0 {
+			return fmt.Errorf("the tag %s dose not exists", deleteName)
+		}
+		fmt.Printf("Deleted the tag %s\n", deleteName)
+		return nil
+	}
+
+	tags, err := s.db.GetTagsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get tags: %w", err)
+	}
+	if len(tags) == 0 {
+		fmt.Println("You have no tags yet")
+		return nil
+	}
+	for _, tag := range tags {
+		fmt.Printf("%-20s %v posts\n", tag.Name, tag.PostCount)
+	}
+	return nil
+}
+
+func handlerSearch(s *state, cmd command, user database.User) error {
+	filter := postFilter{Limit: searchDefaultLimit}
+
+	args, err := popPostFilterFlags(cmd.Arguments, &filter)
+	if err != nil {
+		return err
+	}
+	args, content := popFlag(args, "--content")
+	args, limitValue, err := popFlagValue(args, "--limit")
+	if err != nil {
+		return err
+	}
+	if limitValue != "" {
+		filter.Limit, err = strconv.Atoi(limitValue)
+		if err != nil || filter.Limit < 1 {
+			return fmt.Errorf("invalid limit: %s", limitValue)
+		}
+	}
+	filter.Query = strings.Join(args, " ")
+	if strings.TrimSpace(filter.Query) == "" {
+		return errors.New("search requires a query")
+	}
+
+	posts, err := s.db.GetPostsForUser(context.Background(), filter.params(user.ID))
+	if err != nil {
+		return fmt.Errorf("failed to search posts: %w", err)
+	}
+	if len(posts) == 0 {
+		fmt.Printf("No posts match %q\n", filter.Query)
+		return nil
+	}
+
+	return printPosts(s, posts, userLocation(user), content)
+}
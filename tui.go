@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	tuiPostLimit = 200
+	tuiHelp      = "Tab: switch pane  r: read/unread  s: star  o/Enter: open  a: mark all read  u: unread only  R: refresh  q: quit"
+)
+
+// tui is the interactive browser started by "gator tui". The left pane
+// selects a postFilter, which the post list in the middle shows.
+type tui struct {
+	state      *state
+	user       database.User
+	app        *tview.Application
+	feeds      *tview.TreeView
+	posts      *tview.Table
+	preview    *tview.TextView
+	status     *tview.TextView
+	filter     postFilter
+	unreadOnly bool
+	rows       []database.GetPostsForUserRow
+}
+
+func handlerTUI(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) != 0 {
+		return fmt.Errorf("tui dosent require any arguments, found %v arguments", cmd.Arguments)
+	}
+
+	t := &tui{
+		state:      s,
+		user:       user,
+		app:        tview.NewApplication(),
+		feeds:      tview.NewTreeView(),
+		posts:      tview.NewTable(),
+		preview:    tview.NewTextView(),
+		status:     tview.NewTextView(),
+		unreadOnly: true,
+	}
+	t.layout()
+
+	err := t.reload()
+	if err != nil {
+		return err
+	}
+	return t.app.Run()
+}
+
+func (t *tui) layout() {
+	t.feeds.SetBorder(true)
+	t.feeds.SetTitle(" Feeds ")
+	t.feeds.SetChangedFunc(func(node *tview.TreeNode) {
+		t.selectFilter(node)
+	})
+	t.feeds.SetSelectedFunc(func(node *tview.TreeNode) {
+		t.selectFilter(node)
+		t.app.SetFocus(t.posts)
+	})
+
+	t.posts.SetBorder(true)
+	t.posts.SetSelectable(true, false)
+	t.posts.SetSelectionChangedFunc(func(row, column int) {
+		t.showPreview(row)
+	})
+	t.posts.SetSelectedFunc(func(row, column int) {
+		t.openPost(row)
+	})
+	t.posts.SetInputCapture(t.handlePostKey)
+
+	t.preview.SetBorder(true)
+	t.preview.SetTitle(" Preview ")
+	t.preview.SetWordWrap(true)
+
+	t.status.SetText(tuiHelp)
+
+	panes := tview.NewFlex().
+		AddItem(t.feeds, 0, 1, true).
+		AddItem(t.posts, 0, 2, false).
+		AddItem(t.preview, 0, 2, false)
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(panes, 0, 1, true).
+		AddItem(t.status, 1, 0, false)
+
+	t.app.SetRoot(root, true)
+	t.app.SetInputCapture(t.handleKey)
+}
+
+// handleKey handles the keys that work in every pane.
+func (t *tui) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	switch {
+	case event.Key() == tcell.KeyTab:
+		t.cycleFocus()
+		return nil
+	case event.Rune() == 'q':
+		t.app.Stop()
+		return nil
+	case event.Rune() == 'u':
+		t.unreadOnly = !t.unreadOnly
+		t.loadPosts()
+		return nil
+	case event.Rune() == 'R':
+		t.refresh()
+		return nil
+	}
+	return event
+}
+
+func (t *tui) handlePostKey(event *tcell.EventKey) *tcell.EventKey {
+	row, _ := t.posts.GetSelection()
+	switch event.Rune() {
+	case 'r':
+		t.toggleRead(row)
+	case 's':
+		t.toggleStar(row)
+	case 'o':
+		t.openPost(row)
+	case 'a':
+		t.markAllRead()
+	default:
+		return event
+	}
+	return nil
+}
+
+func (t *tui) cycleFocus() {
+	switch t.app.GetFocus() {
+	case t.feeds:
+		t.app.SetFocus(t.posts)
+	case t.posts:
+		t.app.SetFocus(t.preview)
+	default:
+		t.app.SetFocus(t.feeds)
+	}
+}
+
+func (t *tui) setStatus(format string, args ...interface{}) {
+	t.status.SetText(fmt.Sprintf(format, args...))
+}
+
+// reload rebuilds the feed tree from the followed feeds and their unread
+// counts, keeping the current selection where possible.
+func (t *tui) reload() error {
+	ctx := context.Background()
+	follows, err := t.state.db.GetFeedFollowsForUser(ctx, t.user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get feed follows for current user: %w", err)
+	}
+	counts, err := t.state.db.GetUnreadCountsForUser(ctx, t.user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get unread counts: %w", err)
+	}
+
+	unread := map[string]int64{}
+	folderUnread := map[string]int64{}
+	var total int64
+	for _, count := range counts {
+		unread[count.FeedUrl] = count.UnreadCount
+		folderUnread[count.Folder.String] += count.UnreadCount
+		total += count.UnreadCount
+	}
+
+	root := tview.NewTreeNode(fmt.Sprintf("All feeds (%d)", total)).SetReference(postFilter{})
+	current := root
+	folders := map[string]*tview.TreeNode{}
+	for _, follow := range follows {
+		parent := root
+		if follow.Folder.Valid {
+			folder, ok := folders[follow.Folder.String]
+			if !ok {
+				filter := postFilter{Folder: follow.Folder.String}
+				text := fmt.Sprintf("%s (%d)", follow.Folder.String, folderUnread[follow.Folder.String])
+				folder = tview.NewTreeNode(tview.Escape(text)).SetReference(filter)
+				folders[follow.Folder.String] = folder
+				root.AddChild(folder)
+				if filter == t.filter {
+					current = folder
+				}
+			}
+			parent = folder
+		}
+
+		filter := postFilter{FeedURL: follow.FeedUrl}
+		text := fmt.Sprintf("%s (%d)", follow.FeedName, unread[follow.FeedUrl])
+		node := tview.NewTreeNode(tview.Escape(text)).SetReference(filter)
+		parent.AddChild(node)
+		if filter == t.filter {
+			current = node
+		}
+	}
+
+	t.feeds.SetRoot(root)
+	t.feeds.SetCurrentNode(current)
+	t.loadPosts()
+	return nil
+}
+
+func (t *tui) selectFilter(node *tview.TreeNode) {
+	filter, ok := node.GetReference().(postFilter)
+	if !ok || filter == t.filter {
+		return
+	}
+	t.filter = filter
+	t.loadPosts()
+}
+
+// loadPosts fills the post list for the current filter.
+func (t *tui) loadPosts() {
+	filter := t.filter
+	filter.Limit = tuiPostLimit
+	filter.UnreadOnly = t.unreadOnly
+
+	rows, err := t.state.db.GetPostsForUser(context.Background(), filter.params(t.user.ID))
+	if err != nil {
+		t.setStatus("Failed to fetch posts: %v", err)
+		return
+	}
+	t.rows = rows
+
+	title := " All posts "
+	if t.unreadOnly {
+		title = " Unread posts "
+	}
+	t.posts.SetTitle(title)
+
+	selected, _ := t.posts.GetSelection()
+	t.posts.Clear()
+	for i := range t.rows {
+		t.renderPostRow(i)
+	}
+	if len(t.rows) == 0 {
+		t.posts.SetCell(0, 0, tview.NewTableCell("No posts").SetSelectable(false))
+		t.preview.Clear()
+		return
+	}
+	t.posts.Select(min(selected, len(t.rows)-1), 0)
+	t.showPreview(min(selected, len(t.rows)-1))
+}
+
+func (t *tui) renderPostRow(row int) {
+	post := t.rows[row]
+	marks := " "
+	if !post.ReadAt.Valid {
+		marks = "●"
+	}
+	if post.StarredAt.Valid {
+		marks += "★"
+	} else {
+		marks += " "
+	}
+
+	color := tcell.ColorWhite
+	if post.ReadAt.Valid {
+		color = tcell.ColorGray
+	}
+	t.posts.SetCell(row, 0, tview.NewTableCell(marks).SetTextColor(tcell.ColorYellow))
+	t.posts.SetCell(row, 1, tview.NewTableCell(tview.Escape(post.FeedName)).SetTextColor(color).SetMaxWidth(16))
+	t.posts.SetCell(row, 2, tview.NewTableCell(tview.Escape(post.Title)).SetTextColor(color).SetExpansion(1))
+}
+
+func (t *tui) post(row int) (database.GetPostsForUserRow, bool) {
+	if row < 0 || row >= len(t.rows) {
+		return database.GetPostsForUserRow{}, false
+	}
+	return t.rows[row], true
+}
+
+func (t *tui) showPreview(row int) {
+	post, ok := t.post(row)
+	if !ok {
+		return
+	}
+
+	published := "unknown"
+	if post.PublishedAt.Valid {
+		published = formatTime(post.PublishedAt.Time, userLocation(t.user))
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s\n\nFeed: %s\nPublished: %s\nURL: %s\n", post.Title, post.FeedName, published, post.Url)
+	if body := postBody(post.Description, post.Content, post.ArticleHtml, 0); body != "" {
+		fmt.Fprintf(&text, "\n%s\n", body)
+	}
+	t.preview.SetText(text.String())
+	t.preview.ScrollToBeginning()
+}
+
+func (t *tui) toggleRead(row int) {
+	post, ok := t.post(row)
+	if !ok {
+		return
+	}
+	err := setPostRead(context.Background(), t.state.db, t.user.ID, post.ID, !post.ReadAt.Valid)
+	if err != nil {
+		t.setStatus("%v", err)
+		return
+	}
+	t.rows[row].ReadAt.Valid = !post.ReadAt.Valid
+	t.renderPostRow(row)
+}
+
+func (t *tui) toggleStar(row int) {
+	post, ok := t.post(row)
+	if !ok {
+		return
+	}
+	err := setPostStarred(context.Background(), t.state.db, t.user.ID, post.ID, !post.StarredAt.Valid)
+	if err != nil {
+		t.setStatus("%v", err)
+		return
+	}
+	t.rows[row].StarredAt.Valid = !post.StarredAt.Valid
+	t.renderPostRow(row)
+}
+
+// openPost opens the post in the default browser and marks it read.
+func (t *tui) openPost(row int) {
+	post, ok := t.post(row)
+	if !ok {
+		return
+	}
+	err := openBrowser(post.Url)
+	if err != nil {
+		t.setStatus("Failed to open %s: %v", post.Url, err)
+		return
+	}
+	if !post.ReadAt.Valid {
+		t.toggleRead(row)
+	}
+}
+
+func (t *tui) markAllRead() {
+	markAllPostsReadParams := database.MarkAllPostsReadParams{
+		ReadAt:    time.Now().UTC(),
+		UserID:    t.user.ID,
+		FeedUrl:   sql.NullString{String: t.filter.FeedURL, Valid: t.filter.FeedURL != ""},
+		Folder:    sql.NullString{String: t.filter.Folder, Valid: t.filter.Folder != ""},
+		OlderThan: time.Now().UTC(),
+	}
+	err := t.state.db.MarkAllPostsRead(context.Background(), markAllPostsReadParams)
+	if err != nil {
+		t.setStatus("Failed to mark posts as read: %v", err)
+		return
+	}
+	err = t.reload()
+	if err != nil {
+		t.setStatus("%v", err)
+	}
+}
+
+// refresh fetches every followed feed in the background and reloads the
+// panes once done.
+func (t *tui) refresh() {
+	t.setStatus("Fetching feeds...")
+	go func() {
+		follows, err := t.state.db.GetFeedFollowsForUser(context.Background(), t.user.ID)
+		if err != nil {
+			t.app.QueueUpdateDraw(func() { t.setStatus("Failed to get feed follows: %v", err) })
+			return
+		}
+
+		created := 0
+		var failed []string
+		for _, follow := range follows {
+			feed, err := t.state.db.GetFeedByURL(context.Background(), follow.FeedUrl)
+			if err != nil {
+				failed = append(failed, follow.FeedName)
+				continue
+			}
+			n, err := scrapeFeed(t.state, feed, func(string, ...interface{}) {})
+			if err != nil {
+				failed = append(failed, follow.FeedName)
+				continue
+			}
+			created += n
+		}
+
+		t.app.QueueUpdateDraw(func() {
+			err := t.reload()
+			if err != nil {
+				t.setStatus("%v", err)
+				return
+			}
+			if len(failed) > 0 {
+				t.setStatus("%d new posts, failed to fetch %s", created, strings.Join(failed, ", "))
+				return
+			}
+			t.setStatus("%d new posts. %s", created, tuiHelp)
+		})
+	}()
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+const (
+	uiSessionCookie = "gator_session"
+	uiPageSize      = 25
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var uiTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"formatTime": formatTime,
+	"summary":    htmltext.Summary,
+}).ParseFS(templateFS, "templates/*.html"))
+
+// uiSession is the logged in user of a web UI request. Every form posts
+// CSRFToken back, which is derived from the session token.
+type uiSession struct {
+	User      database.User
+	CSRFToken string
+}
+
+type uiPage struct {
+	Title   string
+	Session *uiSession
+	Error   string
+}
+
+type uiFeed struct {
+	Name   string
+	URL    string
+	Link   string
+	Unread int64
+}
+
+type uiFolder struct {
+	Name   string
+	Link   string
+	Unread int64
+	Feeds  []uiFeed
+}
+
+type uiPostsPage struct {
+	uiPage
+	Folders    []uiFolder
+	Unread     int64
+	View       string
+	Feed       string
+	Folder     string
+	Posts      []database.GetPostsForUserRow
+	Location   *time.Location
+	Self       string
+	AllURL     string
+	PrevURL    string
+	NextURL    string
+	ViewURLs   map[string]string
+	HasFilters bool
+}
+
+type uiFeedsPage struct {
+	uiPage
+	Follows    []database.GetFeedFollowsForUserRow
+	Unfollowed []database.Feed
+	Folders    []string
+}
+
+func (srv *server) registerUI(mux *http.ServeMux) {
+	mux.Handle("GET /{$}", http.RedirectHandler("/ui/", http.StatusSeeOther))
+	mux.HandleFunc("GET /ui/login", srv.handleUILoginPage)
+	mux.HandleFunc("POST /ui/login", srv.handleUILogin)
+	mux.HandleFunc("POST /ui/logout", srv.uiAuthenticated(srv.handleUILogout))
+	mux.HandleFunc("GET /ui/{$}", srv.uiAuthenticated(srv.handleUIPosts))
+	mux.HandleFunc("POST /ui/posts/{id}/read", srv.uiAuthenticated(srv.handleUISetPostState(setPostRead)))
+	mux.HandleFunc("POST /ui/posts/{id}/star", srv.uiAuthenticated(srv.handleUISetPostState(setPostStarred)))
+	mux.HandleFunc("POST /ui/markallread", srv.uiAuthenticated(srv.handleUIMarkAllRead))
+	mux.HandleFunc("GET /ui/feeds", srv.uiAuthenticated(srv.handleUIFeeds))
+	mux.HandleFunc("POST /ui/feeds", srv.uiAuthenticated(srv.handleUIAddFeed))
+	mux.HandleFunc("POST /ui/follows", srv.uiAuthenticated(srv.handleUIFollow))
+	mux.HandleFunc("POST /ui/follows/delete", srv.uiAuthenticated(srv.handleUIUnfollow))
+	mux.HandleFunc("POST /ui/follows/folder", srv.uiAuthenticated(srv.handleUISetFolder))
+}
+
+func csrfToken(sessionToken string) string {
+	return hashToken("csrf:" + sessionToken)
+}
+
+// uiAuthenticated resolves the session cookie to a user and sends anyone
+// without a valid session to the login page. Form posts must carry the
+// CSRF token of the session.
+func (srv *server) uiAuthenticated(handler func(w http.ResponseWriter, r *http.Request, session uiSession)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(uiSessionCookie)
+		if err != nil || cookie.Value == "" {
+			http.Redirect(w, r, "/ui/login", http.StatusSeeOther)
+			return
+		}
+
+		getUserBySessionParams := database.GetUserBySessionParams{
+			TokenHash: hashToken(cookie.Value),
+			Now:       time.Now().UTC(),
+		}
+		user, err := srv.state.db.GetUserBySession(r.Context(), getUserBySessionParams)
+		if err == sql.ErrNoRows {
+			http.Redirect(w, r, "/ui/login", http.StatusSeeOther)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to look up session", http.StatusInternalServerError)
+			return
+		}
+
+		session := uiSession{User: user, CSRFToken: csrfToken(cookie.Value)}
+		if r.Method == http.MethodPost && r.PostFormValue("csrf_token") != session.CSRFToken {
+			http.Error(w, "invalid CSRF token", http.StatusForbidden)
+			return
+		}
+		handler(w, r, session)
+	}
+}
+
+func renderUI(w http.ResponseWriter, code int, name string, data interface{}) {
+	var buf strings.Builder
+	err := uiTemplates.ExecuteTemplate(&buf, name, data)
+	if err != nil {
+		log.Printf("failed to render %s: %v", name, err)
+		http.Error(w, "failed to render page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	fmt.Fprint(w, buf.String())
+}
+
+// redirectBack sends the browser to the page named by the next form value,
+// as long as it is a page of the UI.
+func redirectBack(w http.ResponseWriter, r *http.Request, fallback string) {
+	next := r.PostFormValue("next")
+	if !strings.HasPrefix(next, "/ui/") {
+		next = fallback
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+func redirectWithError(w http.ResponseWriter, r *http.Request, path string, err error) {
+	http.Redirect(w, r, path+"?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+}
+
+func (srv *server) handleUILoginPage(w http.ResponseWriter, r *http.Request) {
+	renderUI(w, http.StatusOK, "login.html", uiPage{Title: "Log in"})
+}
+
+// handleUILogin only lets users with a password in, as anyone who can reach
+// the server could otherwise log in as them.
+func (srv *server) handleUILogin(w http.ResponseWriter, r *http.Request) {
+	page := uiPage{Title: "Log in"}
+
+	user, err := srv.state.db.GetUser(r.Context(), r.PostFormValue("username"))
+	if err != nil || checkPassword(user, r.PostFormValue("password")) != nil {
+		page.Error = "Wrong username or password."
+		renderUI(w, http.StatusUnauthorized, "login.html", page)
+		return
+	}
+	if !user.HashedPassword.Valid {
+		page.Error = "Set a password with \"gator password\" to use the web UI."
+		renderUI(w, http.StatusForbidden, "login.html", page)
+		return
+	}
+
+	token, err := createSession(r.Context(), srv.state.db, user)
+	if err != nil {
+		log.Printf("failed to create session: %v", err)
+		http.Error(w, "failed to create session", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     uiSessionCookie,
+		Value:    token,
+		Path:     "/ui",
+		MaxAge:   int(sessionDuration.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/ui/", http.StatusSeeOther)
+}
+
+func (srv *server) handleUILogout(w http.ResponseWriter, r *http.Request, session uiSession) {
+	cookie, err := r.Cookie(uiSessionCookie)
+	if err == nil {
+		err = srv.state.db.DeleteSession(r.Context(), hashToken(cookie.Value))
+		if err != nil {
+			log.Printf("failed to delete session: %v", err)
+		}
+	}
+	http.SetCookie(w, &http.Cookie{Name: uiSessionCookie, Path: "/ui", MaxAge: -1})
+	http.Redirect(w, r, "/ui/login", http.StatusSeeOther)
+}
+
+// uiFolders groups the followed feeds by folder, with unread counts. Feeds
+// without a folder come first, under an empty folder name.
+func (srv *server) uiFolders(r *http.Request, user database.User) ([]uiFolder, int64, error) {
+	follows, err := srv.state.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get follows: %w", err)
+	}
+	counts, err := srv.state.db.GetUnreadCountsForUser(r.Context(), user.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get unread counts: %w", err)
+	}
+
+	unread := map[uuid.UUID]int64{}
+	var total int64
+	for _, count := range counts {
+		unread[count.FeedID] = count.UnreadCount
+		total += count.UnreadCount
+	}
+
+	var folders []uiFolder
+	index := map[string]int{}
+	for _, follow := range follows {
+		i, ok := index[follow.Folder.String]
+		if !ok {
+			i = len(folders)
+			index[follow.Folder.String] = i
+			folders = append(folders, uiFolder{Name: follow.Folder.String})
+		}
+		folders[i].Unread += unread[follow.FeedID]
+		folders[i].Feeds = append(folders[i].Feeds, uiFeed{
+			Name:   follow.FeedName,
+			URL:    follow.FeedUrl,
+			Unread: unread[follow.FeedID],
+		})
+	}
+	sort.SliceStable(folders, func(i, j int) bool {
+		return folders[i].Name < folders[j].Name
+	})
+	return folders, total, nil
+}
+
+func uiPostsURL(view, feed, folder string, offset int) string {
+	query := url.Values{}
+	if view != "unread" {
+		query.Set("view", view)
+	}
+	if feed != "" {
+		query.Set("feed", feed)
+	}
+	if folder != "" {
+		query.Set("folder", folder)
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	if len(query) == 0 {
+		return "/ui/"
+	}
+	return "/ui/?" + query.Encode()
+}
+
+// handleUIPosts lists the posts of all followed feeds, one folder or one
+// feed. The view is unread (the default), starred or all.
+func (srv *server) handleUIPosts(w http.ResponseWriter, r *http.Request, session uiSession) {
+	query := r.URL.Query()
+	page := uiPostsPage{
+		uiPage:   uiPage{Title: "Posts", Session: &session, Error: query.Get("error")},
+		View:     query.Get("view"),
+		Feed:     query.Get("feed"),
+		Folder:   query.Get("folder"),
+		Location: userLocation(session.User),
+	}
+
+	filter := postFilter{Limit: uiPageSize, FeedURL: page.Feed, Folder: page.Folder}
+	switch page.View {
+	case "", "unread":
+		page.View = "unread"
+		filter.UnreadOnly = true
+	case "starred":
+		filter.StarredOnly = true
+	case "all":
+	default:
+		http.Error(w, "unknown view", http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			http.Error(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+		filter.Offset = offset
+	}
+
+	var err error
+	page.Folders, page.Unread, err = srv.uiFolders(r, session.User)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, "failed to get feeds", http.StatusInternalServerError)
+		return
+	}
+
+	page.Posts, err = srv.state.db.GetPostsForUser(r.Context(), filter.params(session.User.ID))
+	if err != nil {
+		log.Printf("failed to get posts: %v", err)
+		http.Error(w, "failed to get posts", http.StatusInternalServerError)
+		return
+	}
+
+	if page.Feed != "" {
+		page.Title = page.Feed
+		for _, folder := range page.Folders {
+			for _, feed := range folder.Feeds {
+				if feed.URL == page.Feed {
+					page.Title = feed.Name
+				}
+			}
+		}
+	} else if page.Folder != "" {
+		page.Title = page.Folder
+	}
+
+	for i := range page.Folders {
+		folder := &page.Folders[i]
+		folder.Link = uiPostsURL(page.View, "", folder.Name, 0)
+		for j := range folder.Feeds {
+			folder.Feeds[j].Link = uiPostsURL(page.View, folder.Feeds[j].URL, "", 0)
+		}
+	}
+
+	page.HasFilters = page.Feed != "" || page.Folder != ""
+	page.Self = uiPostsURL(page.View, page.Feed, page.Folder, filter.Offset)
+	page.AllURL = uiPostsURL(page.View, "", "", 0)
+	page.ViewURLs = map[string]string{}
+	for _, view := range []string{"unread", "starred", "all"} {
+		page.ViewURLs[view] = uiPostsURL(view, page.Feed, page.Folder, 0)
+	}
+	if filter.Offset > 0 {
+		page.PrevURL = uiPostsURL(page.View, page.Feed, page.Folder, max(filter.Offset-uiPageSize, 0))
+	}
+	if len(page.Posts) == uiPageSize {
+		page.NextURL = uiPostsURL(page.View, page.Feed, page.Folder, filter.Offset+uiPageSize)
+	}
+	renderUI(w, http.StatusOK, "posts.html", page)
+}
+
+func (srv *server) handleUISetPostState(set postStateSetter) func(http.ResponseWriter, *http.Request, uiSession) {
+	return func(w http.ResponseWriter, r *http.Request, session uiSession) {
+		postID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid post id", http.StatusBadRequest)
+			return
+		}
+		value, err := strconv.ParseBool(r.PostFormValue("value"))
+		if err != nil {
+			http.Error(w, "invalid value", http.StatusBadRequest)
+			return
+		}
+
+		_, err = getFollowedPost(r.Context(), srv.state.db, session.User.ID, postID)
+		if err == sql.ErrNoRows {
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("failed to get post: %v", err)
+			http.Error(w, "failed to get post", http.StatusInternalServerError)
+			return
+		}
+
+		err = set(r.Context(), srv.state.db, session.User.ID, postID, value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		redirectBack(w, r, "/ui/")
+	}
+}
+
+func (srv *server) handleUIMarkAllRead(w http.ResponseWriter, r *http.Request, session uiSession) {
+	feed := r.PostFormValue("feed")
+	folder := r.PostFormValue("folder")
+	markAllPostsReadParams := database.MarkAllPostsReadParams{
+		ReadAt:    time.Now().UTC(),
+		UserID:    session.User.ID,
+		FeedUrl:   sql.NullString{String: feed, Valid: feed != ""},
+		Folder:    sql.NullString{String: folder, Valid: folder != ""},
+		OlderThan: time.Now().UTC(),
+	}
+	err := srv.state.db.MarkAllPostsRead(r.Context(), markAllPostsReadParams)
+	if err != nil {
+		log.Printf("failed to mark posts as read: %v", err)
+		http.Error(w, "failed to mark posts as read", http.StatusInternalServerError)
+		return
+	}
+	redirectBack(w, r, "/ui/")
+}
+
+func (srv *server) handleUIFeeds(w http.ResponseWriter, r *http.Request, session uiSession) {
+	page := uiFeedsPage{
+		uiPage: uiPage{Title: "Feeds", Session: &session, Error: r.URL.Query().Get("error")},
+	}
+
+	var err error
+	page.Follows, err = srv.state.db.GetFeedFollowsForUser(r.Context(), session.User.ID)
+	if err != nil {
+		http.Error(w, "failed to get follows", http.StatusInternalServerError)
+		return
+	}
+	feeds, err := srv.state.db.GetFeeds(r.Context())
+	if err != nil {
+		http.Error(w, "failed to get feeds", http.StatusInternalServerError)
+		return
+	}
+
+	followed := map[uuid.UUID]bool{}
+	seenFolders := map[string]bool{}
+	for _, follow := range page.Follows {
+		followed[follow.FeedID] = true
+		if follow.Folder.Valid && !seenFolders[follow.Folder.String] {
+			seenFolders[follow.Folder.String] = true
+			page.Folders = append(page.Folders, follow.Folder.String)
+		}
+	}
+	for _, feed := range feeds {
+		if !followed[feed.ID] {
+			page.Unfollowed = append(page.Unfollowed, feed)
+		}
+	}
+	renderUI(w, http.StatusOK, "feeds.html", page)
+}
+
+func (srv *server) handleUIAddFeed(w http.ResponseWriter, r *http.Request, session uiSession) {
+	name := strings.TrimSpace(r.PostFormValue("name"))
+	feedURL := strings.TrimSpace(r.PostFormValue("url"))
+	if name == "" || feedURL == "" {
+		redirectWithError(w, r, "/ui/feeds", fmt.Errorf("name and url are required"))
+		return
+	}
+
+	_, err := addFeed(r.Context(), srv.state, session.User, name, feedURL)
+	if err != nil {
+		redirectWithError(w, r, "/ui/feeds", err)
+		return
+	}
+	http.Redirect(w, r, "/ui/feeds", http.StatusSeeOther)
+}
+
+func (srv *server) handleUIFollow(w http.ResponseWriter, r *http.Request, session uiSession) {
+	_, err := followFeed(r.Context(), srv.state, session.User, r.PostFormValue("feed_url"))
+	if err != nil {
+		redirectWithError(w, r, "/ui/feeds", err)
+		return
+	}
+	http.Redirect(w, r, "/ui/feeds", http.StatusSeeOther)
+}
+
+func (srv *server) handleUIUnfollow(w http.ResponseWriter, r *http.Request, session uiSession) {
+	unfollowArgs := database.DeleteFeedFollowByUserAndFeedURLParams{
+		UserID: session.User.ID,
+		Url:    r.PostFormValue("feed_url"),
+	}
+	err := srv.state.db.DeleteFeedFollowByUserAndFeedURL(r.Context(), unfollowArgs)
+	if err != nil {
+		redirectWithError(w, r, "/ui/feeds", fmt.Errorf("failed to unfollow feed: %w", err))
+		return
+	}
+	http.Redirect(w, r, "/ui/feeds", http.StatusSeeOther)
+}
+
+func (srv *server) handleUISetFolder(w http.ResponseWriter, r *http.Request, session uiSession) {
+	folder := strings.TrimSpace(r.PostFormValue("folder"))
+	_, err := setFolder(r.Context(), srv.state.db, session.User, r.PostFormValue("feed_url"), folder)
+	if err != nil {
+		redirectWithError(w, r, "/ui/feeds", err)
+		return
+	}
+	http.Redirect(w, r, "/ui/feeds", http.StatusSeeOther)
+}
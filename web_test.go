@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleUISetPostStateRequiresFollow(t *testing.T) {
+	s := newTestState(t)
+	srv := &server{state: s}
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	feed := createTestFeed(t, s, alice, "https://example.com/feed")
+	post := createTestPost(t, s, feed, "https://example.com/a", time.Now().UTC())
+
+	tests := []struct {
+		name    string
+		session uiSession
+		want    int
+	}{
+		{"follower", uiSession{User: alice}, http.StatusSeeOther},
+		{"not following", uiSession{User: bob}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"value": {"true"}}
+			r := httptest.NewRequest(http.MethodPost, "/ui/posts/"+post.ID.String()+"/read", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.SetPathValue("id", post.ID.String())
+			w := httptest.NewRecorder()
+
+			srv.handleUISetPostState(setPostRead)(w, r, tt.session)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/Romasav/gator/internal/webhook"
+	"github.com/google/uuid"
+)
+
+const (
+	webhookLogDefaultLimit = 20
+	webhookMaxConcurrent   = 8
+	// webhookLogRetention is how long delivery attempts stay in the log.
+	webhookLogRetention = 30 * 24 * time.Hour
+)
+
+// webhookSlots bounds the deliveries in flight across all feeds, so a feed
+// with many new posts does not open a connection per post and hook at once.
+var webhookSlots = make(chan struct{}, webhookMaxConcurrent)
+
+func handlerWebhook(s *state, cmd command, user database.User) error {
+	if len(cmd.Arguments) == 0 {
+		return errors.New("webhook requires a subcommand (add, list, remove, test or log)")
+	}
+	args := cmd.Arguments[1:]
+
+	switch cmd.Arguments[0] {
+	case "add":
+		return webhookAdd(s, args, user)
+	case "list":
+		if len(args) != 0 {
+			return fmt.Errorf("webhook list dosent require any arguments, found %v arguments", args)
+		}
+		webhooks, err := s.db.GetWebhooksForUser(context.Background(), user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get webhooks: %w", err)
+		}
+		location := userLocation(user)
+		for _, hook := range webhooks {
+			var filters []string
+			if hook.FeedUrl.Valid {
+				filters = append(filters, "feed "+hook.FeedUrl.String)
+			}
+			if hook.Keyword.Valid {
+				filters = append(filters, fmt.Sprintf("keyword %q", hook.Keyword.String))
+			}
+			if len(filters) == 0 {
+				filters = append(filters, "all followed feeds")
+			}
+			fmt.Printf("%s  %s (%s), created %s\n", hook.ID, hook.Url, strings.Join(filters, ", "), formatTime(hook.CreatedAt, location))
+		}
+	case "remove":
+		if len(args) != 1 {
+			return fmt.Errorf("webhook remove requires exactly 1 argument (webhook id), found %v arguments", len(args))
+		}
+		id, err := uuid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid webhook id: %s", args[0])
+		}
+		deleteWebhookParams := database.DeleteWebhookParams{
+			ID:     id,
+			UserID: user.ID,
+		}
+		deleted, err := s.db.DeleteWebhook(context.Background(), deleteWebhookParams)
+		if err != nil {
+			return fmt.Errorf("failed to remove webhook: %w", err)
+		}
+		if deleted == 0 {
+			return errors.New("the webhook dose not exists")
+		}
+		fmt.Println("The webhook has been removed.")
+	case "test":
+		if len(args) != 1 {
+			return fmt.Errorf("webhook test requires exactly 1 argument (webhook id), found %v arguments", len(args))
+		}
+		id, err := uuid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid webhook id: %s", args[0])
+		}
+		getWebhookParams := database.GetWebhookParams{
+			ID:     id,
+			UserID: user.ID,
+		}
+		hook, err := s.db.GetWebhook(context.Background(), getWebhookParams)
+		if err == sql.ErrNoRows {
+			return errors.New("the webhook dose not exists")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get webhook: %w", err)
+		}
+		err = deliverWebhook(s, webhook.NewSender(), hook, uuid.NullUUID{}, webhook.Payload{Event: webhook.EventPing})
+		if err != nil {
+			return fmt.Errorf("failed to deliver webhook: %w", err)
+		}
+		fmt.Println("The webhook has been delivered.")
+	case "log":
+		return webhookLog(s, args, user)
+	default:
+		return fmt.Errorf("unknown webhook subcommand %v, expected add, list, remove, test or log", cmd.Arguments[0])
+	}
+
+	return nil
+}
+
+func webhookAdd(s *state, args []string, user database.User) error {
+	args, feedURL, err := popFlagValue(args, "--feed")
+	if err != nil {
+		return err
+	}
+	args, keyword, err := popFlagValue(args, "--keyword")
+	if err != nil {
+		return err
+	}
+	args, secret, err := popFlagValue(args, "--secret")
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("webhook add requires exactly 1 argument (url), found %v arguments", len(args))
+	}
+	hookURL, err := url.Parse(args[0])
+	if err != nil || (hookURL.Scheme != "http" && hookURL.Scheme != "https") || hookURL.Host == "" {
+		return fmt.Errorf("invalid webhook url: %s", args[0])
+	}
+
+	createWebhookParams := database.CreateWebhookParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Url:       hookURL.String(),
+		Secret:    secret,
+		Keyword:   sql.NullString{String: keyword, Valid: keyword != ""},
+		CreatedAt: time.Now().UTC(),
+	}
+	if feedURL != "" {
+		feed, err := s.db.GetFeedByURL(context.Background(), feedURL)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("feed %s dose not exists", feedURL)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get feed: %w", err)
+		}
+		// Hooks only fire for feeds their owner follows.
+		getFeedFollowParams := database.GetFeedFollowParams{
+			UserID: user.ID,
+			FeedID: feed.ID,
+		}
+		_, err = s.db.GetFeedFollow(context.Background(), getFeedFollowParams)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("you are not following the feed %s", feedURL)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get feed follow: %w", err)
+		}
+		createWebhookParams.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if createWebhookParams.Secret == "" {
+		createWebhookParams.Secret, err = newToken()
+		if err != nil {
+			return err
+		}
+	}
+
+	hook, err := s.db.CreateWebhook(context.Background(), createWebhookParams)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	fmt.Printf("Created webhook %s for %s\n", hook.ID, hook.Url)
+	if secret == "" {
+		fmt.Printf("Requests are signed with this secret. It will not be shown again:\n%s\n", hook.Secret)
+	}
+	return nil
+}
+
+func webhookLog(s *state, args []string, user database.User) error {
+	args, limitValue, err := popFlagValue(args, "--limit")
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("webhook log takes at most 1 argument (webhook id), found %v arguments", len(args))
+	}
+
+	getWebhookDeliveriesParams := database.GetWebhookDeliveriesParams{
+		UserID: user.ID,
+		Limit:  webhookLogDefaultLimit,
+	}
+	if len(args) > 0 {
+		id, err := uuid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid webhook id: %s", args[0])
+		}
+		getWebhookDeliveriesParams.WebhookID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	if limitValue != "" {
+		limit, err := strconv.Atoi(limitValue)
+		if err != nil || limit < 1 {
+			return fmt.Errorf("invalid limit: %s", limitValue)
+		}
+		getWebhookDeliveriesParams.Limit = int32(limit)
+	}
+
+	deliveries, err := s.db.GetWebhookDeliveries(context.Background(), getWebhookDeliveriesParams)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	location := userLocation(user)
+	for _, delivery := range deliveries {
+		result := "ok"
+		if delivery.Error.Valid {
+			result = delivery.Error.String
+		}
+		if delivery.StatusCode.Valid {
+			result = fmt.Sprintf("%v %s", delivery.StatusCode.Int32, result)
+		}
+		subject := "ping"
+		if delivery.PostTitle.Valid {
+			subject = delivery.PostTitle.String
+		}
+		fmt.Printf("%s  %s  attempt %v: %s\n", formatTime(delivery.CreatedAt, location), delivery.WebhookUrl, delivery.Attempt, result)
+		fmt.Printf("    %s\n", subject)
+	}
+	return nil
+}
+
+// dispatchWebhooks delivers every post in posts to the webhooks interested
+// in feed. Deliveries run in the background, so retrying a slow or failing
+// endpoint does not hold up fetching the feeds after this one.
+func dispatchWebhooks(s *state, feed database.Feed, posts []database.Post, warn func(format string, args ...interface{})) {
+	if len(posts) == 0 {
+		return
+	}
+	hooks, err := s.db.GetWebhooksForFeed(context.Background(), feed.ID)
+	if err != nil {
+		warn("Error getting webhooks: %v", err)
+		return
+	}
+
+	sender := webhook.NewSender()
+	for _, hook := range hooks {
+		for _, post := range posts {
+			matches, err := webhookMatches(s, hook, post)
+			if err != nil {
+				warn("Error checking webhook %s: %v", hook.Url, err)
+				continue
+			}
+			if !matches {
+				continue
+			}
+			payload := webhook.Payload{
+				Event: webhook.EventPostCreated,
+				Feed: &webhook.PayloadFeed{
+					ID:   feed.ID.String(),
+					Name: feed.Name,
+					URL:  feed.Url,
+				},
+				Post: &webhook.PayloadPost{
+					ID:          post.ID.String(),
+					Title:       post.Title,
+					URL:         post.Url,
+					Description: post.Description.String,
+					CreatedAt:   post.CreatedAt,
+				},
+			}
+			if post.PublishedAt.Valid {
+				payload.Post.PublishedAt = &post.PublishedAt.Time
+			}
+
+			go func(hook database.Webhook, postID uuid.UUID) {
+				webhookSlots <- struct{}{}
+				defer func() { <-webhookSlots }()
+				err := deliverWebhook(s, sender, hook, uuid.NullUUID{UUID: postID, Valid: true}, payload)
+				if err != nil {
+					warn("Error delivering webhook %s: %v", hook.Url, err)
+				}
+			}(hook, post.ID)
+		}
+	}
+}
+
+// pruneWebhookDeliveries removes the delivery attempts that are older than
+// webhookLogRetention from the log.
+func pruneWebhookDeliveries(s *state) (int64, error) {
+	n, err := s.db.DeleteWebhookDeliveriesBefore(context.Background(), time.Now().UTC().Add(-webhookLogRetention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune webhook log: %w", err)
+	}
+	return n, nil
+}
+
+// webhookMatches reports whether post should be sent to hook: it has to
+// contain the hook's keyword, if any, and must not have been muted for the
+// hook's owner by a filter rule.
+func webhookMatches(s *state, hook database.Webhook, post database.Post) (bool, error) {
+	if hook.Keyword.Valid {
+		keyword := strings.ToLower(hook.Keyword.String)
+		if !strings.Contains(strings.ToLower(post.Title), keyword) &&
+			!strings.Contains(strings.ToLower(post.Description.String), keyword) {
+			return false, nil
+		}
+	}
+
+	isPostMutedParams := database.IsPostMutedParams{
+		UserID: hook.UserID,
+		PostID: post.ID,
+	}
+	muted, err := s.db.IsPostMuted(context.Background(), isPostMutedParams)
+	if err != nil {
+		return false, fmt.Errorf("failed to get post state: %w", err)
+	}
+	return !muted, nil
+}
+
+func deliverWebhook(s *state, sender *webhook.Sender, hook database.Webhook, postID uuid.NullUUID, payload webhook.Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	deliveryID := uuid.New()
+	return sender.Send(context.Background(), hook.Url, hook.Secret, payload.Event, deliveryID.String(), body, func(attempt webhook.Attempt) {
+		createWebhookDeliveryParams := database.CreateWebhookDeliveryParams{
+			ID:         uuid.New(),
+			WebhookID:  hook.ID,
+			PostID:     postID,
+			Attempt:    int32(attempt.Number),
+			StatusCode: sql.NullInt32{Int32: int32(attempt.StatusCode), Valid: attempt.StatusCode != 0},
+			CreatedAt:  time.Now().UTC(),
+		}
+		if attempt.Err != nil {
+			createWebhookDeliveryParams.Error = sql.NullString{String: attempt.Err.Error(), Valid: true}
+		}
+		err := s.db.CreateWebhookDelivery(context.Background(), createWebhookDeliveryParams)
+		if err != nil {
+			fmt.Printf("Error logging webhook delivery: %v\n", err)
+		}
+	})
+}
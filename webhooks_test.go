@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Romasav/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestWebhookAddRequiresFollow(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	createTestFeed(t, s, alice, "https://example.com/followed")
+	createTestFeed(t, s, bob, "https://example.com/other")
+
+	err := webhookAdd(s, []string{"https://hooks.example.com", "--feed", "https://example.com/other", "--secret", "s"}, alice)
+	if err == nil {
+		t.Error("webhook add accepted a feed the user does not follow")
+	}
+	err = webhookAdd(s, []string{"https://hooks.example.com", "--feed", "https://example.com/followed", "--secret", "s"}, alice)
+	if err != nil {
+		t.Errorf("webhook add failed for a followed feed: %v", err)
+	}
+}
+
+func TestGetWebhooksForFeedRequiresFollow(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "https://example.com/feed")
+	for _, args := range [][]string{
+		{"https://hooks.example.com/all", "--secret", "s"},
+		{"https://hooks.example.com/feed", "--feed", feed.Url, "--secret", "s"},
+	} {
+		if err := webhookAdd(s, args, alice); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hooks, err := s.db.GetWebhooksForFeed(context.Background(), feed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 2 {
+		t.Errorf("got %d webhooks while following the feed, want 2", len(hooks))
+	}
+
+	unfollowParams := database.DeleteFeedFollowByUserAndFeedURLParams{
+		UserID: alice.ID,
+		Url:    feed.Url,
+	}
+	if err := s.db.DeleteFeedFollowByUserAndFeedURL(context.Background(), unfollowParams); err != nil {
+		t.Fatal(err)
+	}
+	hooks, err = s.db.GetWebhooksForFeed(context.Background(), feed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 0 {
+		t.Errorf("got %d webhooks after unfollowing the feed, want none", len(hooks))
+	}
+}
+
+func TestDispatchWebhooksDoesNotWait(t *testing.T) {
+	release := make(chan struct{})
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer endpoint.Close()
+
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "https://example.com/feed")
+	post := createTestPost(t, s, feed, "https://example.com/a", time.Now().UTC())
+	if err := webhookAdd(s, []string{endpoint.URL, "--secret", "s"}, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	dispatched := make(chan struct{})
+	go func() {
+		dispatchWebhooks(s, feed, []database.Post{post}, t.Logf)
+		close(dispatched)
+	}()
+	select {
+	case <-dispatched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("dispatchWebhooks waited for the endpoint to answer")
+	}
+	close(release)
+
+	getWebhookDeliveriesParams := database.GetWebhookDeliveriesParams{UserID: alice.ID, Limit: 10}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		deliveries, err := s.db.GetWebhookDeliveries(context.Background(), getWebhookDeliveriesParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deliveries) == 1 && deliveries[0].StatusCode.Int32 == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("deliveries = %+v, want one successful attempt", deliveries)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPruneWebhookDeliveries(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	if err := webhookAdd(s, []string{"https://hooks.example.com", "--secret", "s"}, alice); err != nil {
+		t.Fatal(err)
+	}
+	hooks, err := s.db.GetWebhooksForUser(context.Background(), alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, age := range []time.Duration{time.Hour, webhookLogRetention - time.Hour, webhookLogRetention + time.Hour, 90 * 24 * time.Hour} {
+		createWebhookDeliveryParams := database.CreateWebhookDeliveryParams{
+			ID:         uuid.New(),
+			WebhookID:  hooks[0].ID,
+			Attempt:    1,
+			StatusCode: sql.NullInt32{Int32: http.StatusOK, Valid: true},
+			CreatedAt:  time.Now().UTC().Add(-age),
+		}
+		if err := s.db.CreateWebhookDelivery(context.Background(), createWebhookDeliveryParams); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := pruneWebhookDeliveries(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("pruned %d deliveries, want 2", n)
+	}
+	deliveries, err := s.db.GetWebhookDeliveries(context.Background(), database.GetWebhookDeliveriesParams{UserID: alice.ID, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 2 {
+		t.Errorf("%d deliveries left, want 2", len(deliveries))
+	}
+}